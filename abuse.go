@@ -0,0 +1,156 @@
+package ipnetblocks
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// AbuseConfidence is the level of confidence that the abuse contact is the right one.
+type AbuseConfidence string
+
+const (
+	// AbuseConfidenceHigh means that the emails are abuse contacts of the most specific netblock.
+	AbuseConfidenceHigh AbuseConfidence = "high"
+
+	// AbuseConfidenceMedium means that the emails are abuse contacts of a parent netblock.
+	AbuseConfidenceMedium AbuseConfidence = "medium"
+
+	// AbuseConfidenceLow means that the emails are organization emails of a netblock.
+	AbuseConfidenceLow AbuseConfidence = "low"
+
+	// AbuseConfidenceGuess means that the emails were found in remarks or description of a netblock.
+	AbuseConfidenceGuess AbuseConfidence = "guess"
+
+	// AbuseConfidenceNone means that no emails were found.
+	AbuseConfidenceNone AbuseConfidence = "none"
+)
+
+// AbuseSource is the field the abuse contact emails were taken from.
+type AbuseSource string
+
+const (
+	// AbuseSourceAbuseContact means that the emails were taken from the AbuseContact field.
+	AbuseSourceAbuseContact AbuseSource = "abuseContact"
+
+	// AbuseSourceOrg means that the emails were taken from the Org.Email field.
+	AbuseSourceOrg AbuseSource = "org"
+
+	// AbuseSourceRemarks means that the emails were found in the Remarks or Description fields.
+	AbuseSourceRemarks AbuseSource = "remarks"
+)
+
+// Abuse is the resolved abuse contact of an IP address.
+type Abuse struct {
+	// Emails is the list of unique emails to send abuse reports to.
+	Emails []string
+
+	// Inetnum is the netblock the emails were taken from. Nil when no emails were found.
+	Inetnum *Inetnum
+
+	// Source is the field of the netblock the emails were taken from.
+	Source AbuseSource
+
+	// Confidence is the level of confidence that the emails are the right ones.
+	Confidence AbuseConfidence
+}
+
+// AbuseContact returns the abuse contact of the IP address. It looks for the AbuseContact field of the most
+// specific netblock first, then walks up its parent netblocks. Failing that, it falls back to organization
+// emails and then to emails found in remarks and description, from the most specific netblock up.
+func (service ipNetblocksServiceOp) AbuseContact(ctx context.Context, ip net.IP, opts ...Option) (*Abuse, error) {
+	ipNetblocksResp, _, err := service.GetByIP(ctx, ip, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveAbuse(ipNetblocksResp.Result.Inetnums), nil
+}
+
+// ResolveAbuse returns the abuse contact from the netblocks returned by GetByIP.
+func ResolveAbuse(inetnums []Inetnum) *Abuse {
+	chain := NewHierarchy(inetnums, PreferRegistry).Chain()
+
+	// Walk from the most specific netblock up.
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	for i := range chain {
+		var emails []string
+		for _, contact := range chain[i].AbuseContact {
+			emails = append(emails, contact.Emails()...)
+		}
+
+		if emails = uniqueEmails(emails); len(emails) > 0 {
+			confidence := AbuseConfidenceHigh
+			if i > 0 {
+				confidence = AbuseConfidenceMedium
+			}
+
+			return &Abuse{Emails: emails, Inetnum: &chain[i], Source: AbuseSourceAbuseContact, Confidence: confidence}
+		}
+	}
+
+	for i := range chain {
+		if emails := preferAbuse(chain[i].Org.Emails()); len(emails) > 0 {
+			return &Abuse{Emails: emails, Inetnum: &chain[i], Source: AbuseSourceOrg, Confidence: AbuseConfidenceLow}
+		}
+	}
+
+	for i := range chain {
+		var emails []string
+		for _, line := range append(append([]string(nil), chain[i].Remarks...), chain[i].Description...) {
+			emails = append(emails, extractEmails(line)...)
+		}
+
+		if emails = preferAbuse(emails); len(emails) > 0 {
+			return &Abuse{Emails: emails, Inetnum: &chain[i], Source: AbuseSourceRemarks, Confidence: AbuseConfidenceGuess}
+		}
+	}
+
+	return &Abuse{Confidence: AbuseConfidenceNone}
+}
+
+// emailRegexp matches email addresses in free-form text.
+var emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+
+// extractEmails returns email addresses found in the text.
+func extractEmails(s string) []string {
+	return emailRegexp.FindAllString(s, -1)
+}
+
+// uniqueEmails returns lowercased emails without duplicates, in the original order.
+func uniqueEmails(emails []string) []string {
+	seen := make(map[string]bool, len(emails))
+
+	var unique []string
+	for _, email := range emails {
+		email = strings.ToLower(email)
+		if !seen[email] {
+			seen[email] = true
+			unique = append(unique, email)
+		}
+	}
+
+	return unique
+}
+
+// preferAbuse returns unique emails which mention abuse, or all unique emails if none of them does.
+func preferAbuse(emails []string) []string {
+	emails = uniqueEmails(emails)
+
+	var abuse []string
+	for _, email := range emails {
+		if strings.Contains(email, "abuse") {
+			abuse = append(abuse, email)
+		}
+	}
+
+	if len(abuse) > 0 {
+		return abuse
+	}
+
+	return emails
+}
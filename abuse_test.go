@@ -0,0 +1,117 @@
+package ipnetblocks
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// TestResolveAbuse tests the ResolveAbuse function.
+func TestResolveAbuse(t *testing.T) {
+	parent := Inetnum{
+		Inetnum:      "8.0.0.0 - 8.127.255.255",
+		Netname:      "LVLT-ORG-8-8",
+		AbuseContact: []Contact{{Email: "Abuse@Level3.com"}},
+		Org:          Organization{Email: "ipaddressing@level3.com"},
+	}
+
+	child := Inetnum{
+		Inetnum:     "8.8.8.0 - 8.8.8.255",
+		Netname:     "LVLT-GOGL-8-8-8",
+		Org:         Organization{Email: "arin-contact@google.com\nnetwork-abuse@google.com"},
+		Remarks:     []string{"Please report abuse to security@google.com"},
+		Description: []string{"Google LLC"},
+	}
+
+	tests := []struct {
+		name       string
+		inetnums   []Inetnum
+		emails     []string
+		netname    string
+		source     AbuseSource
+		confidence AbuseConfidence
+	}{
+		{
+			name: "most specific",
+			inetnums: []Inetnum{parent, func() Inetnum {
+				c := child
+				c.AbuseContact = []Contact{{Email: "network-abuse@google.com"}, {Email: "NETWORK-ABUSE@google.com"}}
+				return c
+			}()},
+			emails:     []string{"network-abuse@google.com"},
+			netname:    "LVLT-GOGL-8-8-8",
+			source:     AbuseSourceAbuseContact,
+			confidence: AbuseConfidenceHigh,
+		},
+		{
+			name:       "parent",
+			inetnums:   []Inetnum{child, parent},
+			emails:     []string{"abuse@level3.com"},
+			netname:    "LVLT-ORG-8-8",
+			source:     AbuseSourceAbuseContact,
+			confidence: AbuseConfidenceMedium,
+		},
+		{
+			name:       "organization",
+			inetnums:   []Inetnum{child},
+			emails:     []string{"network-abuse@google.com"},
+			netname:    "LVLT-GOGL-8-8-8",
+			source:     AbuseSourceOrg,
+			confidence: AbuseConfidenceLow,
+		},
+		{
+			name: "remarks",
+			inetnums: []Inetnum{func() Inetnum {
+				c := child
+				c.Org = Organization{}
+				return c
+			}()},
+			emails:     []string{"security@google.com"},
+			netname:    "LVLT-GOGL-8-8-8",
+			source:     AbuseSourceRemarks,
+			confidence: AbuseConfidenceGuess,
+		},
+		{
+			name:       "none",
+			inetnums:   []Inetnum{{Inetnum: "8.8.8.0 - 8.8.8.255"}},
+			confidence: AbuseConfidenceNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveAbuse(tt.inetnums)
+
+			if !reflect.DeepEqual(got.Emails, tt.emails) || got.Source != tt.source || got.Confidence != tt.confidence {
+				t.Errorf("ResolveAbuse() = %+v, want %v from %v with %v confidence", got, tt.emails, tt.source, tt.confidence)
+			}
+
+			if tt.netname != "" && (got.Inetnum == nil || got.Inetnum.Netname != tt.netname) {
+				t.Errorf("ResolveAbuse() got netblock %v, want %v", got.Inetnum, tt.netname)
+			}
+		})
+	}
+}
+
+// TestIPNetblocksAbuseContact tests the AbuseContact function.
+func TestIPNetblocksAbuseContact(t *testing.T) {
+	server := pagedServer(map[string]string{
+		"8.8.8.8/": `{"search":"8.8.8.8","result":{"count":1,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"8.8.8.0 - 8.8.8.255","org":{"email":"arin-contact@google.com\nnetwork-abuse@google.com"}}]}}`,
+	})
+	defer server.Close()
+
+	api := newAPI(server, "/")
+
+	got, err := api.AbuseContact(context.Background(), net.ParseIP("8.8.8.8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got.Emails, []string{"network-abuse@google.com"}) || got.Confidence != AbuseConfidenceLow {
+		t.Errorf("IPNetblocks.AbuseContact() = %+v", got)
+	}
+
+	_, err = api.AbuseContact(context.Background(), nil)
+	checkErr(t, err, `invalid argument: "ip" can not be empty`)
+}
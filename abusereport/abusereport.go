@@ -0,0 +1,268 @@
+// Package abusereport generates abuse report messages addressed to the abuse contacts of IP netblocks.
+// Messages are only generated, sending them is up to the caller.
+package abusereport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/netip"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// userAgent is the name of the generator put into reports.
+const userAgent = "ip-netblocks-go"
+
+// ErrNoAbuseContact is returned when the netblocks have no emails to address the report to.
+var ErrNoAbuseContact = errors.New("no abuse contact found")
+
+// Evidence is a single piece of evidence of the incident.
+type Evidence struct {
+	// Time is the time of the event.
+	Time time.Time
+
+	// Line is the log line describing the event.
+	Line string
+}
+
+// Incident is the abusive activity to report.
+type Incident struct {
+	// IP is the offending IP address.
+	IP netip.Addr
+
+	// Category is the X-ARF category, e.g. "abuse", "fraud" or "info". Default: "abuse".
+	Category string
+
+	// Type is the X-ARF report type, e.g. "login-attack", "portscan" or "malware". Default: "info".
+	Type string
+
+	// Service is the name of the attacked service, e.g. "ssh". Omitted when empty.
+	Service string
+
+	// SchemaURL is the URL of the X-ARF schema of the report type. Default: derived from the category and
+	// the type.
+	SchemaURL string
+
+	// Port is the attacked port. Omitted when zero.
+	Port int
+
+	// Description is the human-readable description of the incident.
+	Description string
+
+	// Evidence is the list of events, e.g. log lines.
+	Evidence []Evidence
+
+	// OriginalHeaders is the header section of the offending email message. It's attached to ARF reports as
+	// the "text/rfc822-headers" part. When empty, minimal headers with the IP and the time of the first event
+	// are attached instead, as ARF requires the part.
+	OriginalHeaders string
+}
+
+// Params is used to render reports. From is mandatory.
+type Params struct {
+	// From is the email address of the reporter, e.g. "abuse@example.com". Display names are not allowed,
+	// use Organization instead.
+	From string
+
+	// Organization is the name of the reporting organization. Omitted when empty.
+	Organization string
+
+	// Date is the date of the report. Default: the current time.
+	Date time.Time
+
+	// ReportID is the unique ID of the report. Default: derived from the incident.
+	ReportID string
+}
+
+// Report is the generated message.
+type Report struct {
+	// To is the list of recipients.
+	To []string
+
+	// Abuse is the resolved abuse contact the report is addressed to.
+	Abuse *ipnetblocks.Abuse
+
+	// Message is the MIME message with headers.
+	Message []byte
+}
+
+// WriteTo writes the message.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.Message)
+
+	return int64(n), err
+}
+
+// prepare validates the arguments and resolves the abuse contact and defaults.
+func prepare(incident *Incident, inetnums []ipnetblocks.Inetnum, params *Params) (*ipnetblocks.Abuse, error) {
+	if !incident.IP.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "ip", Message: "can not be empty"}
+	}
+
+	if params.From == "" {
+		return nil, &ipnetblocks.ArgError{Name: "from", Message: "can not be empty"}
+	}
+
+	// From is put into headers as is, so anything but a bare address could inject headers.
+	if addr, err := mail.ParseAddress(params.From); err != nil || addr.Name != "" || addr.Address != params.From {
+		return nil, &ipnetblocks.ArgError{Name: "from", Message: "must be a bare email address"}
+	}
+
+	abuse := ipnetblocks.ResolveAbuse(inetnums)
+	if len(abuse.Emails) == 0 {
+		return nil, ErrNoAbuseContact
+	}
+
+	incident.IP = incident.IP.Unmap()
+	incident.Category = defaults.String(incident.Category, "abuse")
+	incident.Type = defaults.String(incident.Type, "info")
+
+	incident.Evidence = append([]Evidence(nil), incident.Evidence...)
+	sort.SliceStable(incident.Evidence, func(i, j int) bool {
+		return incident.Evidence[i].Time.Before(incident.Evidence[j].Time)
+	})
+
+	if params.Date.IsZero() {
+		params.Date = time.Now()
+	}
+
+	if params.ReportID == "" {
+		params.ReportID = reportID(*incident, params.From)
+	}
+
+	return abuse, nil
+}
+
+// reportID returns the ID derived from the incident, so that the same incident always gets the same ID.
+func reportID(incident Incident, from string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", incident.IP, incident.Category, incident.Type)
+	for _, e := range incident.Evidence {
+		fmt.Fprintf(h, "%s %s\n", e.Time.UTC().Format(time.RFC3339Nano), e.Line)
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:32] + "@" + domain
+}
+
+// message is a MIME message under construction.
+type message struct {
+	header bytes.Buffer
+	body   bytes.Buffer
+	parts  *multipart.Writer
+}
+
+// newMessage starts the multipart message with the common headers.
+func newMessage(abuse *ipnetblocks.Abuse, incident Incident, params Params, contentType string) *message {
+	m := &message{}
+	m.parts = multipart.NewWriter(&m.body)
+
+	// The boundary is derived from the report ID to keep the output reproducible.
+	sum := sha256.Sum256([]byte(params.ReportID))
+	if err := m.parts.SetBoundary("report-" + hex.EncodeToString(sum[:16])); err != nil {
+		panic(err)
+	}
+
+	from := params.From
+	if params.Organization != "" {
+		from = mime.QEncoding.Encode("utf-8", params.Organization) + " <" + params.From + ">"
+	}
+
+	m.setHeader("From", from)
+	m.setHeader("To", strings.Join(abuse.Emails, ", "))
+	m.setHeader("Subject", mime.QEncoding.Encode("utf-8",
+		fmt.Sprintf("Abuse report for %s (%s/%s)", incident.IP, incident.Category, incident.Type)))
+	m.setHeader("Date", params.Date.Format(time.RFC1123Z))
+	m.setHeader("Message-ID", "<"+params.ReportID+">")
+	m.setHeader("MIME-Version", "1.0")
+	m.setHeader("Content-Type", contentType+"; boundary=\""+m.parts.Boundary()+"\"")
+
+	return m
+}
+
+// setHeader writes the message header.
+func (m *message) setHeader(name, value string) {
+	fmt.Fprintf(&m.header, "%s: %s\r\n", name, value)
+}
+
+// addPart writes the MIME part with the content type.
+func (m *message) addPart(contentType, disposition string, content []byte) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	if disposition != "" {
+		header.Set("Content-Disposition", disposition)
+	}
+
+	part, err := m.parts.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = part.Write(crlf(content))
+
+	return err
+}
+
+// bytes finishes the message and returns it.
+func (m *message) bytes() ([]byte, error) {
+	if err := m.parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return append(append(m.header.Bytes(), "\r\n"...), m.body.Bytes()...), nil
+}
+
+// humanReadable returns the human-readable part of the report.
+func humanReadable(abuse *ipnetblocks.Abuse, incident Incident) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "We have detected abusive activity from %s, which belongs to your network", incident.IP)
+	if abuse.Inetnum != nil {
+		fmt.Fprintf(&b, " %s (%s)", abuse.Inetnum.Inetnum, abuse.Inetnum.Netname)
+	}
+	b.WriteString(".\n")
+
+	if incident.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", incident.Description)
+	}
+
+	if len(incident.Evidence) > 0 {
+		b.WriteString("\nEvidence:\n")
+		b.Write(evidenceLog(incident))
+	}
+
+	return b.Bytes()
+}
+
+// evidenceLog returns the evidence as log lines prefixed with timestamps.
+func evidenceLog(incident Incident) []byte {
+	var b bytes.Buffer
+	for _, e := range incident.Evidence {
+		fmt.Fprintf(&b, "%s %s\n", e.Time.UTC().Format(time.RFC3339), strings.TrimRight(e.Line, "\r\n"))
+	}
+
+	return b.Bytes()
+}
+
+// crlf converts line endings to CRLF as required by MIME.
+func crlf(b []byte) []byte {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+
+	return bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,283 @@
+package abusereport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// testInetnums is the sample list of netblocks for testing.
+var testInetnums = []ipnetblocks.Inetnum{
+	{
+		Inetnum:      "8.0.0.0 - 8.127.255.255",
+		Netname:      "LVLT-ORG-8-8",
+		AbuseContact: []ipnetblocks.Contact{{Email: "abuse@level3.com"}},
+	},
+	{
+		Inetnum:      "8.8.8.0 - 8.8.8.255",
+		Netname:      "LVLT-GOGL-8-8-8",
+		AbuseContact: []ipnetblocks.Contact{{Email: "network-abuse@google.com"}},
+	},
+}
+
+// testIncident is the sample incident for testing.
+var testIncident = Incident{
+	IP:      netip.MustParseAddr("8.8.8.8"),
+	Type:    "login-attack",
+	Service: "ssh",
+	Port:    22,
+	Evidence: []Evidence{
+		{Time: time.Date(2022, 5, 1, 10, 0, 5, 0, time.UTC), Line: "sshd: Failed password for root from 8.8.8.8"},
+		{Time: time.Date(2022, 5, 1, 10, 0, 1, 0, time.UTC), Line: "sshd: Invalid user admin from 8.8.8.8"},
+	},
+}
+
+// testParams is the sample report params for testing.
+var testParams = Params{
+	From:         "abuse@example.com",
+	Organization: "Example Inc.",
+	Date:         time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// parseReport parses the message and returns its header, media type and parts.
+func parseReport(t *testing.T, report *Report) (mail.Header, string, map[string]string, []string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(bytes.NewReader(report.Message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []string
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts = append(parts, part.Header.Get("Content-Type")+"\n"+strings.ReplaceAll(string(body), "\r\n", "\n"))
+	}
+
+	return msg.Header, mediaType, params, parts
+}
+
+// TestARF tests the ARF function.
+func TestARF(t *testing.T) {
+	report, err := ARF(testIncident, testInetnums, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"network-abuse@google.com"}; !reflect.DeepEqual(report.To, want) {
+		t.Errorf("To: got = %v, want %v", report.To, want)
+	}
+
+	header, mediaType, params, parts := parseReport(t, report)
+
+	if mediaType != "multipart/report" || params["report-type"] != "feedback-report" {
+		t.Errorf("Content-Type: got = %q", header.Get("Content-Type"))
+	}
+
+	from, err := header.AddressList("From")
+	if err != nil || from[0].Name != "Example Inc." || from[0].Address != "abuse@example.com" {
+		t.Errorf("From: got = %v, %v", from, err)
+	}
+
+	if got := header.Get("To"); got != "network-abuse@google.com" {
+		t.Errorf("To header: got = %q", got)
+	}
+
+	if got, want := header.Get("Date"), "Sun, 01 May 2022 12:00:00 +0000"; got != want {
+		t.Errorf("Date: got = %q, want %q", got, want)
+	}
+
+	want := []string{
+		`text/plain; charset="utf-8"` + "\n" +
+			"We have detected abusive activity from 8.8.8.8, which belongs to your network " +
+			"8.8.8.0 - 8.8.8.255 (LVLT-GOGL-8-8-8).\n" +
+			"\n" +
+			"Evidence:\n" +
+			"2022-05-01T10:00:01Z sshd: Invalid user admin from 8.8.8.8\n" +
+			"2022-05-01T10:00:05Z sshd: Failed password for root from 8.8.8.8\n",
+		"message/feedback-report\n" +
+			"Feedback-Type: abuse\n" +
+			"User-Agent: ip-netblocks-go\n" +
+			"Version: 1\n" +
+			"Arrival-Date: Sun, 01 May 2022 10:00:01 +0000\n" +
+			"Incidents: 2\n" +
+			"Source-IP: 8.8.8.8\n",
+		`text/rfc822-headers; charset="utf-8"` + "\n" +
+			"Received: from [8.8.8.8]; Sun, 01 May 2022 10:00:01 +0000\n" +
+			"Date: Sun, 01 May 2022 10:00:01 +0000\n",
+	}
+
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("parts: got = %q, want %q", parts, want)
+	}
+
+	withHeaders := testIncident
+	withHeaders.OriginalHeaders = "Received: from mail.example.net ([8.8.8.8])\nSubject: Cheap watches\n"
+
+	withReport, err := ARF(withHeaders, testInetnums, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, parts = parseReport(t, withReport)
+	if len(parts) != 3 || parts[2] != `text/rfc822-headers; charset="utf-8"`+"\n"+withHeaders.OriginalHeaders {
+		t.Errorf("parts with original headers: got = %q", parts)
+	}
+
+	again, err := ARF(testIncident, testInetnums, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(report.Message, again.Message) {
+		t.Error("the same incident produced different messages")
+	}
+}
+
+// TestXARF tests the XARF function.
+func TestXARF(t *testing.T) {
+	report, err := XARF(testIncident, testInetnums, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, mediaType, _, parts := parseReport(t, report)
+
+	if mediaType != "multipart/mixed" || header.Get("X-XARF") != "PLAIN" {
+		t.Errorf("headers: got = %v", header)
+	}
+
+	if len(parts) != 3 {
+		t.Fatalf("parts: got = %q", parts)
+	}
+
+	id := strings.Trim(header.Get("Message-ID"), "<>")
+
+	wantReport := `text/plain; charset="utf-8"; name="report.txt"` + "\n" +
+		"Reported-From: abuse@example.com\n" +
+		"Category: abuse\n" +
+		"Report-Type: login-attack\n" +
+		"Service: ssh\n" +
+		"Version: 0.2\n" +
+		"User-Agent: ip-netblocks-go\n" +
+		"Date: Sun, 01 May 2022 10:00:01 +0000\n" +
+		"Source-Type: ipv4\n" +
+		"Source: 8.8.8.8\n" +
+		"Port: 22\n" +
+		"Occurrences: 2\n" +
+		"Attachment: text/plain\n" +
+		"Report-ID: " + id + "\n" +
+		"Schema-URL: http://www.x-arf.org/schema/abuse_login-attack_0.1.2.json\n"
+
+	if parts[1] != wantReport {
+		t.Errorf("report: got = %q, want %q", parts[1], wantReport)
+	}
+
+	wantLog := `text/plain; charset="utf-8"; name="logfile.log"` + "\n" +
+		"2022-05-01T10:00:01Z sshd: Invalid user admin from 8.8.8.8\n" +
+		"2022-05-01T10:00:05Z sshd: Failed password for root from 8.8.8.8\n"
+
+	if parts[2] != wantLog {
+		t.Errorf("log: got = %q, want %q", parts[2], wantLog)
+	}
+}
+
+// TestReportErrors tests errors of the ARF function.
+func TestReportErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		incident Incident
+		inetnums []ipnetblocks.Inetnum
+		params   Params
+		err      string
+	}{
+		{
+			name:     "no ip",
+			inetnums: testInetnums,
+			params:   testParams,
+			err:      `invalid argument: "ip" can not be empty`,
+		},
+		{
+			name:     "no from",
+			incident: testIncident,
+			inetnums: testInetnums,
+			err:      `invalid argument: "from" can not be empty`,
+		},
+		{
+			name:     "from with header",
+			incident: testIncident,
+			inetnums: testInetnums,
+			params:   Params{From: "abuse@example.com\r\nBcc: victim@example.org"},
+			err:      `invalid argument: "from" must be a bare email address`,
+		},
+		{
+			name:     "from with display name",
+			incident: testIncident,
+			inetnums: testInetnums,
+			params:   Params{From: "Abuse Desk <abuse@example.com>"},
+			err:      `invalid argument: "from" must be a bare email address`,
+		},
+		{
+			name:     "from with spaces",
+			incident: testIncident,
+			inetnums: testInetnums,
+			params:   Params{From: " abuse@example.com"},
+			err:      `invalid argument: "from" must be a bare email address`,
+		},
+		{
+			name:     "from without domain",
+			incident: testIncident,
+			inetnums: testInetnums,
+			params:   Params{From: "abuse"},
+			err:      `invalid argument: "from" must be a bare email address`,
+		},
+		{
+			name:     "no contact",
+			incident: testIncident,
+			inetnums: []ipnetblocks.Inetnum{{Inetnum: "8.8.8.0 - 8.8.8.255"}},
+			params:   testParams,
+			err:      ErrNoAbuseContact.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ARF(tt.incident, tt.inetnums, tt.params)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ARF() error = %v, wantErr %v", err, tt.err)
+			}
+
+			if tt.name == "no contact" && !errors.Is(err, ErrNoAbuseContact) {
+				t.Errorf("ARF() error = %v, want ErrNoAbuseContact", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package abusereport
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// ARF returns the RFC 5965 Abuse Reporting Format message about the incident addressed to the abuse contact
+// resolved from the netblocks returned by GetByIP. The feedback type is "fraud" for the "fraud" category and
+// "abuse" otherwise.
+func ARF(incident Incident, inetnums []ipnetblocks.Inetnum, params Params) (*Report, error) {
+	abuse, err := prepare(&incident, inetnums, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMessage(abuse, incident, params, "multipart/report; report-type=feedback-report")
+
+	if err = m.addPart(`text/plain; charset="utf-8"`, "", humanReadable(abuse, incident)); err != nil {
+		return nil, err
+	}
+
+	if err = m.addPart("message/feedback-report", "", feedbackReport(incident)); err != nil {
+		return nil, err
+	}
+
+	// RFC 5965 requires the third part, so headers are synthesized when the original ones are unknown.
+	headers := []byte(incident.OriginalHeaders)
+	if len(headers) == 0 {
+		headers = syntheticHeaders(incident, params)
+	}
+
+	if err = m.addPart(`text/rfc822-headers; charset="utf-8"`, "", headers); err != nil {
+		return nil, err
+	}
+
+	message, err := m.bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{To: abuse.Emails, Abuse: abuse, Message: message}, nil
+}
+
+// syntheticHeaders returns minimal headers of the offending message: where it came from and when.
+func syntheticHeaders(incident Incident, params Params) []byte {
+	date := params.Date
+	if len(incident.Evidence) > 0 {
+		date = incident.Evidence[0].Time
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Received: from [%s]; %s\n", incident.IP, date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Date: %s\n", date.Format(time.RFC1123Z))
+
+	return b.Bytes()
+}
+
+// feedbackReport returns the machine-readable part of the ARF message.
+func feedbackReport(incident Incident) []byte {
+	var b bytes.Buffer
+
+	feedbackType := "abuse"
+	if incident.Category == "fraud" {
+		feedbackType = "fraud"
+	}
+
+	fmt.Fprintf(&b, "Feedback-Type: %s\n", feedbackType)
+	fmt.Fprintf(&b, "User-Agent: %s\n", userAgent)
+	b.WriteString("Version: 1\n")
+
+	if len(incident.Evidence) > 0 {
+		fmt.Fprintf(&b, "Arrival-Date: %s\n", incident.Evidence[0].Time.Format(time.RFC1123Z))
+		fmt.Fprintf(&b, "Incidents: %d\n", len(incident.Evidence))
+	}
+
+	fmt.Fprintf(&b, "Source-IP: %s\n", incident.IP)
+
+	return b.Bytes()
+}
@@ -0,0 +1,103 @@
+package abusereport
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// xarfVersion is the version of the X-ARF format.
+const xarfVersion = "0.2"
+
+// XARF returns the X-ARF 0.2 message about the incident addressed to the abuse contact resolved from the
+// netblocks returned by GetByIP. The evidence is attached as the log file.
+func XARF(incident Incident, inetnums []ipnetblocks.Inetnum, params Params) (*Report, error) {
+	abuse, err := prepare(&incident, inetnums, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMessage(abuse, incident, params, "multipart/mixed")
+	m.setHeader("X-XARF", "PLAIN")
+
+	if err = m.addPart(`text/plain; charset="utf-8"`, "", humanReadable(abuse, incident)); err != nil {
+		return nil, err
+	}
+
+	err = m.addPart(`text/plain; charset="utf-8"; name="report.txt"`, "", xarfReport(incident, params))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(incident.Evidence) > 0 {
+		err = m.addPart(`text/plain; charset="utf-8"; name="logfile.log"`, `attachment; filename="logfile.log"`,
+			evidenceLog(incident))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message, err := m.bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{To: abuse.Emails, Abuse: abuse, Message: message}, nil
+}
+
+// xarfReport returns the machine-readable YAML part of the X-ARF message.
+func xarfReport(incident Incident, params Params) []byte {
+	var b bytes.Buffer
+
+	field := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\n", name, yamlString(value))
+	}
+
+	field("Reported-From", params.From)
+	field("Category", incident.Category)
+	field("Report-Type", incident.Type)
+	if incident.Service != "" {
+		field("Service", incident.Service)
+	}
+	field("Version", xarfVersion)
+	field("User-Agent", userAgent)
+
+	date := params.Date
+	if len(incident.Evidence) > 0 {
+		date = incident.Evidence[0].Time
+	}
+	field("Date", date.Format(time.RFC1123Z))
+
+	sourceType := "ipv4"
+	if incident.IP.Is6() {
+		sourceType = "ipv6"
+	}
+	field("Source-Type", sourceType)
+	field("Source", incident.IP.String())
+	if incident.Port != 0 {
+		fmt.Fprintf(&b, "Port: %d\n", incident.Port)
+	}
+	if len(incident.Evidence) > 0 {
+		fmt.Fprintf(&b, "Occurrences: %d\n", len(incident.Evidence))
+		field("Attachment", "text/plain")
+	}
+	field("Report-ID", params.ReportID)
+	field("Schema-URL", defaults.String(incident.SchemaURL,
+		"http://www.x-arf.org/schema/"+incident.Category+"_"+incident.Type+"_0.1.2.json"))
+
+	return b.Bytes()
+}
+
+// yamlString quotes the value when it can't be written as the plain YAML scalar.
+func yamlString(value string) string {
+	if value == "" || strings.ContainsAny(value, "#'\"\n\\") || strings.Contains(value, ": ") ||
+		strings.TrimSpace(value) != value || strings.ContainsAny(value[:1], "!&*-?[]{}|>%@`,") {
+		return fmt.Sprintf("%q", value)
+	}
+
+	return value
+}
@@ -0,0 +1,138 @@
+package cidrset
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// CountrySummary is the address space registered in a country.
+type CountrySummary struct {
+	// Code is the two letters country code. Codes which are not in ISO 3166 are kept upper-cased.
+	// Empty for netblocks without a country.
+	Code string
+
+	// Country is the ISO 3166 entry of the country. Zero when Code is not in ISO 3166.
+	Country ipnetblocks.Country
+
+	// Netblocks is the number of netblocks registered in the country.
+	Netblocks int
+
+	// Addresses is the set of addresses whose most specific netblock is registered in the country.
+	Addresses Set
+
+	// IPv4 is the number of IPv4 addresses in Addresses.
+	IPv4 *big.Int
+
+	// IPv6 is the number of IPv6 addresses in Addresses.
+	IPv6 *big.Int
+
+	// ASNs is the sorted list of autonomous systems announcing netblocks registered in the country.
+	ASNs []int
+}
+
+// SummarizeCountries returns the address space, netblocks and autonomous systems per country of the netblocks,
+// e.g. returned by Sweep, ordered by country code. The country of a netblock is taken from its Country field,
+// falling back to the organization's country. Each address is counted in the country of its most specific
+// netblock which has one, so nested netblocks are not counted twice.
+func SummarizeCountries(inetnums []ipnetblocks.Inetnum) ([]CountrySummary, error) {
+	type entry struct {
+		r       ipnetblocks.Range
+		inetnum ipnetblocks.Inetnum
+		code    string
+	}
+
+	seen := make(map[string]bool, len(inetnums))
+	entries := make([]entry, 0, len(inetnums))
+
+	for _, inetnum := range inetnums {
+		if seen[inetnum.Key()] {
+			continue
+		}
+		seen[inetnum.Key()] = true
+
+		r, err := inetnum.Range()
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry{r: r, inetnum: inetnum, code: countryCode(inetnum)})
+	}
+
+	// Wider netblocks go first, so that more specific ones take their addresses over.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return RangeCount(entries[i].r).Cmp(RangeCount(entries[j].r)) > 0
+	})
+
+	summaries := make(map[string]*CountrySummary)
+	asns := make(map[string]map[int]bool)
+
+	for _, e := range entries {
+		summary, ok := summaries[e.code]
+		if !ok {
+			summary = &CountrySummary{Code: e.code}
+			summary.Country, _ = ipnetblocks.LookupCountry(e.code)
+			summaries[e.code] = summary
+			asns[e.code] = make(map[int]bool)
+		}
+
+		summary.Netblocks++
+		if e.inetnum.AS.ASN != 0 {
+			asns[e.code][e.inetnum.AS.ASN] = true
+		}
+
+		block := FromRanges(e.r)
+		if e.code == "" {
+			// A netblock without a country only gets addresses no other netblock has claimed.
+			for _, other := range summaries {
+				block = block.Difference(other.Addresses)
+			}
+		} else {
+			for _, other := range summaries {
+				other.Addresses = other.Addresses.Difference(block)
+			}
+		}
+
+		summary.Addresses = summary.Addresses.Union(block)
+	}
+
+	list := make([]CountrySummary, 0, len(summaries))
+	for code, summary := range summaries {
+		summary.IPv4 = summary.Addresses.IPv4().Count()
+		summary.IPv6 = summary.Addresses.IPv6().Count()
+
+		for asn := range asns[code] {
+			summary.ASNs = append(summary.ASNs, asn)
+		}
+		sort.Ints(summary.ASNs)
+
+		list = append(list, *summary)
+	}
+
+	// Netblocks without a country go last.
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Code == "") != (list[j].Code == "") {
+			return list[j].Code == ""
+		}
+
+		return list[i].Code < list[j].Code
+	})
+
+	return list, nil
+}
+
+// countryCode returns the normalized country code of the netblock.
+func countryCode(inetnum ipnetblocks.Inetnum) string {
+	code := strings.TrimSpace(inetnum.Country)
+	if code == "" {
+		code = strings.TrimSpace(inetnum.Org.Country)
+	}
+
+	if country, ok := ipnetblocks.LookupCountry(code); ok {
+		return country.Code
+	}
+
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,56 @@
+package cidrset
+
+import (
+	"reflect"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// TestSummarizeCountries tests the SummarizeCountries function.
+func TestSummarizeCountries(t *testing.T) {
+	inetnums := []ipnetblocks.Inetnum{
+		{Inetnum: "10.0.0.0 - 10.0.255.255", Country: "us", AS: ipnetblocks.AS{ASN: 3356}},
+		{Inetnum: "10.0.1.0 - 10.0.1.255", Country: "CA", AS: ipnetblocks.AS{ASN: 577}},
+		{Inetnum: "10.0.1.0 - 10.0.1.255", Country: "CA", AS: ipnetblocks.AS{ASN: 577}},
+		{Inetnum: "10.0.2.0 - 10.0.2.255", Org: ipnetblocks.Organization{Country: "USA"}, AS: ipnetblocks.AS{ASN: 15169}},
+		{Inetnum: "10.1.0.0 - 10.1.0.255"},
+		{Inetnum: "10.1.0.0 - 10.1.0.127", Country: "EU"},
+		{Inetnum: "2001:db8:: - 2001:db8::ffff", Country: "CA"},
+	}
+
+	summaries, err := SummarizeCountries(inetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		code      string
+		name      string
+		netblocks int
+		ipv4      string
+		ipv6      string
+		asns      []int
+	}
+
+	var got []row
+	for _, s := range summaries {
+		got = append(got, row{s.Code, s.Country.Name, s.Netblocks, s.IPv4.String(), s.IPv6.String(), s.ASNs})
+	}
+
+	want := []row{
+		{"CA", "Canada", 2, "256", "65536", []int{577}},
+		{"EU", "", 1, "128", "0", nil},
+		{"US", "United States of America", 2, "65280", "0", []int{3356, 15169}},
+		{"", "", 1, "128", "0", nil},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeCountries() got = %v, want %v", got, want)
+	}
+
+	_, err = SummarizeCountries([]ipnetblocks.Inetnum{{Inetnum: "bad"}})
+	if err == nil {
+		t.Errorf("SummarizeCountries() expected error")
+	}
+}
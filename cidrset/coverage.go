@@ -0,0 +1,184 @@
+package cidrset
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Coverage describes how the netblocks returned for a queried prefix cover it.
+type Coverage struct {
+	// Prefix is the queried prefix.
+	Prefix netip.Prefix
+
+	// Covered is the set of addresses of the prefix which belong to at least one netblock.
+	Covered Set
+
+	// Gaps is the set of addresses of the prefix which don't belong to any netblock.
+	Gaps Set
+
+	// Overlaps is the list of ranges which belong to more than one netblock, in address order.
+	Overlaps []Overlap
+
+	// Percent is the percentage of the prefix addresses covered by netblocks.
+	Percent float64
+
+	// ByRegistry is the percentage of the prefix addresses covered by netblocks of each source registry.
+	ByRegistry map[ipnetblocks.Registry]float64
+
+	// ByASN is the percentage of the prefix addresses covered by netblocks of each autonomous system.
+	// Netblocks without AS data are counted under zero.
+	ByASN map[int]float64
+}
+
+// Overlap is a range which belongs to several netblocks.
+type Overlap struct {
+	// Range is the overlapping range.
+	Range ipnetblocks.Range
+
+	// Inetnums is the list of netblocks which contain the range.
+	Inetnums []ipnetblocks.Inetnum
+}
+
+// Analyze returns the coverage of the queried prefix by the netblocks, e.g. returned by GetByCIDR.
+// Parts of netblocks outside the prefix are ignored.
+func Analyze(prefix netip.Prefix, inetnums []ipnetblocks.Inetnum) (*Coverage, error) {
+	if !prefix.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "prefix", Message: "can not be empty"}
+	}
+
+	prefix = prefix.Masked()
+	parent := New(prefix)
+
+	type clipped struct {
+		r       ipnetblocks.Range
+		inetnum ipnetblocks.Inetnum
+	}
+
+	var blocks []clipped
+	byRegistry := make(map[ipnetblocks.Registry]Set)
+	byASN := make(map[int]Set)
+
+	for _, inetnum := range inetnums {
+		r, err := inetnum.Range()
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse netblock %q: %w", inetnum.Inetnum, err)
+		}
+
+		inside := FromRanges(r).Intersect(parent)
+		if inside.IsEmpty() {
+			continue
+		}
+
+		blocks = append(blocks, clipped{r: inside.ranges[0], inetnum: inetnum})
+		byRegistry[inetnum.Registry()] = byRegistry[inetnum.Registry()].Union(inside)
+		byASN[inetnum.AS.ASN] = byASN[inetnum.AS.ASN].Union(inside)
+	}
+
+	coverage := &Coverage{
+		Prefix:     prefix,
+		ByRegistry: make(map[ipnetblocks.Registry]float64, len(byRegistry)),
+		ByASN:      make(map[int]float64, len(byASN)),
+	}
+
+	var covered []ipnetblocks.Range
+	for _, block := range blocks {
+		covered = append(covered, block.r)
+	}
+
+	coverage.Covered = FromRanges(covered...)
+	coverage.Gaps = coverage.Covered.Complement(prefix)
+
+	total := parent.Count()
+	coverage.Percent = percent(coverage.Covered.Count(), total)
+
+	for registry, set := range byRegistry {
+		coverage.ByRegistry[registry] = percent(set.Count(), total)
+	}
+
+	for asn, set := range byASN {
+		coverage.ByASN[asn] = percent(set.Count(), total)
+	}
+
+	// Boundaries split the prefix into segments each of which belongs to the same netblocks.
+	var boundaries []netip.Addr
+	for _, block := range blocks {
+		boundaries = append(boundaries, block.r.First)
+		if next := block.r.Last.Next(); next.IsValid() {
+			boundaries = append(boundaries, next)
+		}
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Less(boundaries[j])
+	})
+
+	for i, first := range boundaries {
+		if i > 0 && boundaries[i-1] == first {
+			continue
+		}
+
+		var inetnums []ipnetblocks.Inetnum
+		for _, block := range blocks {
+			if block.r.Contains(first) {
+				inetnums = append(inetnums, block.inetnum)
+			}
+		}
+
+		if len(inetnums) < 2 {
+			continue
+		}
+
+		last := ipnetblocks.PrefixRange(prefix).Last
+		for _, next := range boundaries[i+1:] {
+			if first.Less(next) {
+				last = next.Prev()
+				break
+			}
+		}
+
+		segment := ipnetblocks.Range{First: first, Last: last}
+
+		if n := len(coverage.Overlaps); n > 0 && sameInetnums(coverage.Overlaps[n-1].Inetnums, inetnums) &&
+			coverage.Overlaps[n-1].Range.Last.Next() == first {
+			coverage.Overlaps[n-1].Range.Last = last
+			continue
+		}
+
+		coverage.Overlaps = append(coverage.Overlaps, Overlap{Range: segment, Inetnums: inetnums})
+	}
+
+	return coverage, nil
+}
+
+// percent returns the share of part in total as percentage.
+func percent(part, total *big.Int) float64 {
+	if total.Sign() == 0 {
+		return 0
+	}
+
+	f, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(new(big.Int).Mul(part, big.NewInt(100))),
+		new(big.Float).SetInt(total),
+	).Float64()
+
+	return f
+}
+
+// sameInetnums reports whether both lists consist of the same netblocks.
+func sameInetnums(a, b []ipnetblocks.Inetnum) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Inetnum != b[i].Inetnum || a[i].Source != b[i].Source || a[i].Parent != b[i].Parent {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package cidrset
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// TestAnalyze tests the Analyze function.
+func TestAnalyze(t *testing.T) {
+	inetnums := []ipnetblocks.Inetnum{
+		{Inetnum: "10.0.0.0 - 10.0.0.127", Source: "RIPE", AS: ipnetblocks.AS{ASN: 64500}},
+		{Inetnum: "10.0.0.64 - 10.0.0.95", Source: "RIPE", AS: ipnetblocks.AS{ASN: 64501}},
+		{Inetnum: "10.0.0.192 - 10.0.1.255", Source: "ARIN", AS: ipnetblocks.AS{ASN: 64500}},
+	}
+
+	coverage, err := Analyze(netip.MustParsePrefix("10.0.0.0/24"), inetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := coverage.Covered.String(), "10.0.0.0/25, 10.0.0.192/26"; got != want {
+		t.Errorf("Coverage.Covered = %v, want %v", got, want)
+	}
+
+	if got, want := coverage.Gaps.String(), "10.0.0.128/26"; got != want {
+		t.Errorf("Coverage.Gaps = %v, want %v", got, want)
+	}
+
+	if coverage.Percent != 75 {
+		t.Errorf("Coverage.Percent = %v, want 75", coverage.Percent)
+	}
+
+	if want := map[ipnetblocks.Registry]float64{"RIPE": 50, "ARIN": 25}; !reflect.DeepEqual(coverage.ByRegistry, want) {
+		t.Errorf("Coverage.ByRegistry = %v, want %v", coverage.ByRegistry, want)
+	}
+
+	if want := map[int]float64{64500: 75, 64501: 12.5}; !reflect.DeepEqual(coverage.ByASN, want) {
+		t.Errorf("Coverage.ByASN = %v, want %v", coverage.ByASN, want)
+	}
+
+	if len(coverage.Overlaps) != 1 {
+		t.Fatalf("Coverage.Overlaps = %v, want 1 overlap", coverage.Overlaps)
+	}
+
+	overlap := coverage.Overlaps[0]
+	if overlap.Range.String() != "10.0.0.64 - 10.0.0.95" || len(overlap.Inetnums) != 2 {
+		t.Errorf("Coverage.Overlaps[0] = %v", overlap)
+	}
+
+	_, err = Analyze(netip.Prefix{}, inetnums)
+	if err == nil {
+		t.Errorf("Analyze() expected error")
+	}
+}
@@ -0,0 +1,300 @@
+// Package cidrset implements sets of IPv4 and IPv6 addresses with set algebra over netblocks.
+package cidrset
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Set is an immutable set of IPv4 and IPv6 addresses. The zero value is the empty set.
+type Set struct {
+	// ranges is the sorted list of disjoint and non-adjacent ranges, IPv4 ones first.
+	ranges []ipnetblocks.Range
+}
+
+// New returns the set of addresses covered by the prefixes.
+func New(prefixes ...netip.Prefix) Set {
+	ranges := make([]ipnetblocks.Range, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix.IsValid() {
+			ranges = append(ranges, ipnetblocks.PrefixRange(prefix))
+		}
+	}
+
+	return FromRanges(ranges...)
+}
+
+// FromRanges returns the set of addresses covered by the ranges. Invalid ranges are ignored.
+func FromRanges(ranges ...ipnetblocks.Range) Set {
+	valid := make([]ipnetblocks.Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.IsValid() {
+			valid = append(valid, r)
+		}
+	}
+
+	return Set{ranges: merge(valid)}
+}
+
+// FromInetnums returns the set of addresses covered by the netblocks.
+func FromInetnums(inetnums []ipnetblocks.Inetnum) (Set, error) {
+	ranges := make([]ipnetblocks.Range, 0, len(inetnums))
+	for _, inetnum := range inetnums {
+		r, err := inetnum.Range()
+		if err != nil {
+			return Set{}, fmt.Errorf("cannot parse netblock %q: %w", inetnum.Inetnum, err)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return FromRanges(ranges...), nil
+}
+
+// Ranges returns the minimal list of ranges covering the set, in address order.
+func (s Set) Ranges() []ipnetblocks.Range {
+	return append([]ipnetblocks.Range(nil), s.ranges...)
+}
+
+// Prefixes returns the minimal list of prefixes covering the set, in address order.
+func (s Set) Prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, r := range s.ranges {
+		prefixes = append(prefixes, r.Prefixes()...)
+	}
+
+	return prefixes
+}
+
+// IPv4 returns the subset of IPv4 addresses.
+func (s Set) IPv4() Set {
+	var ranges []ipnetblocks.Range
+	for _, r := range s.ranges {
+		if r.First.Is4() {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return Set{ranges: ranges}
+}
+
+// IPv6 returns the subset of IPv6 addresses.
+func (s Set) IPv6() Set {
+	var ranges []ipnetblocks.Range
+	for _, r := range s.ranges {
+		if r.First.Is6() {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return Set{ranges: ranges}
+}
+
+// IsEmpty reports whether the set has no addresses.
+func (s Set) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Equal reports whether both sets have the same addresses.
+func (s Set) Equal(o Set) bool {
+	if len(s.ranges) != len(o.ranges) {
+		return false
+	}
+
+	for i := range s.ranges {
+		if s.ranges[i] != o.ranges[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of addresses in the set.
+func (s Set) Count() *big.Int {
+	count := new(big.Int)
+	for _, r := range s.ranges {
+		count.Add(count, RangeCount(r))
+	}
+
+	return count
+}
+
+// Contains reports whether the address belongs to the set.
+func (s Set) Contains(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return !s.ranges[i].Last.Less(ip)
+	})
+
+	return i < len(s.ranges) && s.ranges[i].Contains(ip)
+}
+
+// ContainsPrefix reports whether all addresses of the prefix belong to the set.
+func (s Set) ContainsPrefix(prefix netip.Prefix) bool {
+	return s.ContainsRange(ipnetblocks.PrefixRange(prefix))
+}
+
+// ContainsRange reports whether all addresses of the range belong to the set.
+func (s Set) ContainsRange(r ipnetblocks.Range) bool {
+	if !r.IsValid() {
+		return false
+	}
+
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return !s.ranges[i].Last.Less(r.First)
+	})
+
+	return i < len(s.ranges) && s.ranges[i].Contains(r.First) && s.ranges[i].Contains(r.Last)
+}
+
+// Overlaps reports whether at least one address of the prefix belongs to the set.
+func (s Set) Overlaps(prefix netip.Prefix) bool {
+	return !s.Intersect(New(prefix)).IsEmpty()
+}
+
+// Union returns the set of addresses which belong to either set.
+func (s Set) Union(o Set) Set {
+	ranges := make([]ipnetblocks.Range, 0, len(s.ranges)+len(o.ranges))
+	ranges = append(ranges, s.ranges...)
+	ranges = append(ranges, o.ranges...)
+
+	return Set{ranges: merge(ranges)}
+}
+
+// Intersect returns the set of addresses which belong to both sets.
+func (s Set) Intersect(o Set) Set {
+	var ranges []ipnetblocks.Range
+
+	for i, j := 0, 0; i < len(s.ranges) && j < len(o.ranges); {
+		a, b := s.ranges[i], o.ranges[j]
+
+		first, last := maxAddr(a.First, b.First), minAddr(a.Last, b.Last)
+		if !last.Less(first) && first.BitLen() == last.BitLen() {
+			ranges = append(ranges, ipnetblocks.Range{First: first, Last: last})
+		}
+
+		if a.Last.Less(b.Last) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return Set{ranges: ranges}
+}
+
+// Difference returns the set of addresses which belong to the set but not to the other one.
+func (s Set) Difference(o Set) Set {
+	var ranges []ipnetblocks.Range
+
+	j := 0
+	for _, r := range s.ranges {
+		for j < len(o.ranges) && o.ranges[j].Last.Less(r.First) {
+			j++
+		}
+
+		current := r
+		for k := j; k < len(o.ranges) && !r.Last.Less(o.ranges[k].First); k++ {
+			cut := o.ranges[k]
+
+			if current.First.Less(cut.First) {
+				ranges = append(ranges, ipnetblocks.Range{First: current.First, Last: cut.First.Prev()})
+			}
+
+			if !cut.Last.Less(current.Last) {
+				current = ipnetblocks.Range{}
+				break
+			}
+
+			current.First = maxAddr(current.First, cut.Last.Next())
+		}
+
+		if current.IsValid() {
+			ranges = append(ranges, current)
+		}
+	}
+
+	return Set{ranges: ranges}
+}
+
+// Complement returns the set of addresses of the parent block which don't belong to the set.
+func (s Set) Complement(parent netip.Prefix) Set {
+	return New(parent).Difference(s)
+}
+
+// String returns the set as a comma-separated list of prefixes.
+func (s Set) String() string {
+	prefixes := s.Prefixes()
+
+	strs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		strs = append(strs, prefix.String())
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+// RangeCount returns the number of addresses in the range.
+func RangeCount(r ipnetblocks.Range) *big.Int {
+	if !r.IsValid() {
+		return new(big.Int)
+	}
+
+	count := new(big.Int).Sub(addrInt(r.Last), addrInt(r.First))
+
+	return count.Add(count, big.NewInt(1))
+}
+
+// addrInt returns the address as an unsigned integer.
+func addrInt(ip netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(ip.AsSlice())
+}
+
+// merge sorts ranges and merges overlapping and adjacent ones.
+func merge(ranges []ipnetblocks.Range) []ipnetblocks.Range {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].First.Less(ranges[j].First)
+	})
+
+	var merged []ipnetblocks.Range
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].First.BitLen() == r.First.BitLen() {
+			last := &merged[n-1]
+
+			next := last.Last.Next()
+			if !next.IsValid() || !next.Less(r.First) {
+				last.Last = maxAddr(last.Last, r.Last)
+				continue
+			}
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// maxAddr returns the greater of two addresses.
+func maxAddr(a, b netip.Addr) netip.Addr {
+	if a.Less(b) {
+		return b
+	}
+
+	return a
+}
+
+// minAddr returns the lesser of two addresses.
+func minAddr(a, b netip.Addr) netip.Addr {
+	if a.Less(b) {
+		return a
+	}
+
+	return b
+}
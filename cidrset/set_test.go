@@ -0,0 +1,135 @@
+package cidrset
+
+import (
+	"net/netip"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// parse returns the set of the prefixes for testing.
+func parse(prefixes ...string) Set {
+	parsed := make([]netip.Prefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		parsed = append(parsed, netip.MustParsePrefix(prefix))
+	}
+
+	return New(parsed...)
+}
+
+// TestSetAlgebra tests the Union, Intersect, Difference and Complement functions.
+func TestSetAlgebra(t *testing.T) {
+	a := parse("10.0.0.0/24", "10.0.1.0/24", "2001:db8::/32")
+	b := parse("10.0.0.128/25", "10.0.2.0/24", "2001:db8:8000::/33", "fd00::/8")
+
+	tests := []struct {
+		name string
+		got  Set
+		want string
+	}{
+		{
+			name: "new",
+			got:  a,
+			want: "10.0.0.0/23, 2001:db8::/32",
+		},
+		{
+			name: "union",
+			got:  a.Union(b),
+			want: "10.0.0.0/23, 10.0.2.0/24, 2001:db8::/32, fd00::/8",
+		},
+		{
+			name: "intersect",
+			got:  a.Intersect(b),
+			want: "10.0.0.128/25, 2001:db8:8000::/33",
+		},
+		{
+			name: "difference",
+			got:  a.Difference(b),
+			want: "10.0.0.0/25, 10.0.1.0/24, 2001:db8::/33",
+		},
+		{
+			name: "difference of the whole",
+			got:  parse("10.0.0.0/24").Difference(parse("10.0.0.0/8")),
+			want: "",
+		},
+		{
+			name: "complement",
+			got:  parse("10.0.0.64/26", "10.0.0.192/26").Complement(netip.MustParsePrefix("10.0.0.0/24")),
+			want: "10.0.0.0/26, 10.0.0.128/26",
+		},
+		{
+			name: "complement of the whole space",
+			got:  parse("0.0.0.0/1").Complement(netip.MustParsePrefix("0.0.0.0/0")),
+			want: "128.0.0.0/1",
+		},
+		{
+			name: "IPv6 subset",
+			got:  a.Union(b).IPv6(),
+			want: "2001:db8::/32, fd00::/8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetQueries tests the Count, Contains, ContainsPrefix and Overlaps functions.
+func TestSetQueries(t *testing.T) {
+	s, err := FromInetnums([]ipnetblocks.Inetnum{
+		{Inetnum: "10.0.0.0 - 10.0.0.255"},
+		{Inetnum: "10.0.1.0 - 10.0.1.9"},
+		{Inetnum: "2001:db8:: - 2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := s.Count().String(), "79228162514264337593543950602"; got != want {
+		t.Errorf("Set.Count() = %v, want %v", got, want)
+	}
+
+	if got, want := s.IPv4().Count().String(), "266"; got != want {
+		t.Errorf("Set.IPv4().Count() = %v, want %v", got, want)
+	}
+
+	for ip, want := range map[string]bool{
+		"10.0.0.0":        true,
+		"10.0.1.9":        true,
+		"10.0.1.10":       false,
+		"::ffff:10.0.0.1": true,
+		"2001:db8::1":     true,
+		"2001:db9::":      false,
+	} {
+		if got := s.Contains(netip.MustParseAddr(ip)); got != want {
+			t.Errorf("Set.Contains(%v) = %v, want %v", ip, got, want)
+		}
+	}
+
+	for prefix, want := range map[string]bool{
+		"10.0.0.0/24":     true,
+		"10.0.0.0/23":     false,
+		"10.0.1.8/31":     true,
+		"2001:db8:1::/48": true,
+	} {
+		if got := s.ContainsPrefix(netip.MustParsePrefix(prefix)); got != want {
+			t.Errorf("Set.ContainsPrefix(%v) = %v, want %v", prefix, got, want)
+		}
+	}
+
+	if !s.Overlaps(netip.MustParsePrefix("10.0.0.0/8")) || s.Overlaps(netip.MustParsePrefix("192.168.0.0/16")) {
+		t.Errorf("Set.Overlaps() returned unexpected result")
+	}
+
+	if !s.Equal(s.Union(parse("10.0.0.0/25"))) || s.Equal(Set{}) {
+		t.Errorf("Set.Equal() returned unexpected result")
+	}
+
+	_, err = FromInetnums([]ipnetblocks.Inetnum{{Inetnum: "10.0.0.0"}})
+	if err == nil {
+		t.Errorf("FromInetnums() expected error")
+	}
+}
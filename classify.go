@@ -0,0 +1,319 @@
+package ipnetblocks
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProviderCategory is the kind of network an IP address belongs to.
+type ProviderCategory string
+
+const (
+	// ProviderUnknown means that no rule matched.
+	ProviderUnknown ProviderCategory = ""
+
+	// ProviderHosting is the datacenter, hosting or cloud provider.
+	ProviderHosting ProviderCategory = "hosting"
+
+	// ProviderISP is the residential or mobile access provider.
+	ProviderISP ProviderCategory = "isp"
+
+	// ProviderEducation is the educational or research network.
+	ProviderEducation ProviderCategory = "education"
+
+	// ProviderEnterprise is the corporate network.
+	ProviderEnterprise ProviderCategory = "enterprise"
+)
+
+// providerCategories is the order categories with equal scores are chosen in.
+var providerCategories = []ProviderCategory{ProviderHosting, ProviderISP, ProviderEducation, ProviderEnterprise}
+
+// RuleField is the netblock field a classifier rule is matched against.
+type RuleField string
+
+const (
+	// RuleFieldASType matches AS.Type. The pattern is parsed as ASType and compared as a whole.
+	RuleFieldASType RuleField = "asType"
+
+	// RuleFieldASName matches AS.Name.
+	RuleFieldASName RuleField = "asName"
+
+	// RuleFieldNetname matches Netname.
+	RuleFieldNetname RuleField = "netname"
+
+	// RuleFieldDescription matches Description and Remarks lines.
+	RuleFieldDescription RuleField = "description"
+
+	// RuleFieldOrg matches Org.Name.
+	RuleFieldOrg RuleField = "org"
+)
+
+// ClassifierRule is the rule which adds its weight to the category when the pattern is found in the field.
+// Patterns other than AS types are matched as whole words, ignoring case.
+type ClassifierRule struct {
+	// Field is the netblock field to match.
+	Field RuleField
+
+	// Pattern is the keyword to look for.
+	Pattern string
+
+	// Category is the category the rule votes for.
+	Category ProviderCategory
+
+	// Weight is the score the rule adds to the category. Zero disables the rule.
+	Weight int
+}
+
+// ClassificationEvidence is a match of a rule.
+type ClassificationEvidence struct {
+	// Rule is the matched rule.
+	Rule ClassifierRule
+
+	// Inetnum is the range of the netblock the rule matched.
+	Inetnum string
+
+	// Value is the field value the rule matched.
+	Value string
+}
+
+// Classification is the result of classifying an IP address.
+type Classification struct {
+	// Category is the category with the highest score, or ProviderUnknown if no rule matched.
+	Category ProviderCategory
+
+	// Scores is the sum of weights of the matched rules for each category. A rule counts once even if it
+	// matched several netblocks.
+	Scores map[ProviderCategory]int
+
+	// Evidence is the list of matches which voted for Category.
+	Evidence []ClassificationEvidence
+}
+
+// ClassifierParams is used to create Classifier. None of parameters are mandatory and leaving this struct empty
+// makes the classifier use the built-in rules.
+type ClassifierParams struct {
+	// Rules is the list of rules added to the built-in ones. A rule with the same field and pattern as
+	// a built-in rule replaces it.
+	Rules []ClassifierRule
+
+	// NoBuiltinRules makes the classifier use only Rules.
+	NoBuiltinRules bool
+}
+
+// Classifier tells hosting providers from access providers and other networks by netblock data.
+type Classifier struct {
+	rules []classifierRule
+}
+
+// classifierRule is the rule with the compiled pattern.
+type classifierRule struct {
+	ClassifierRule
+	re *regexp.Regexp
+}
+
+//go:embed data/provider-rules.csv
+var providerRulesCSV []byte
+
+// builtinClassifierRules is the parsed list of built-in rules.
+var builtinClassifierRules = mustParseClassifierRules(providerRulesCSV)
+
+// mustParseClassifierRules parses the embedded rules.
+func mustParseClassifierRules(raw []byte) []ClassifierRule {
+	rules, err := ParseClassifierRules(bytes.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+
+	return rules
+}
+
+// BuiltinClassifierRules returns the built-in rules.
+func BuiltinClassifierRules() []ClassifierRule {
+	return append([]ClassifierRule(nil), builtinClassifierRules...)
+}
+
+// ParseClassifierRules parses rules in the CSV format with the "Field,Pattern,Category,Weight" header,
+// e.g. from a local rules file.
+func ParseClassifierRules(r io.Reader) ([]ClassifierRule, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]ClassifierRule, 0, len(records)-1)
+	for i, record := range records[1:] {
+		weight, err := strconv.Atoi(strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("record %d: invalid weight %q", i+2, record[3])
+		}
+
+		rule := ClassifierRule{
+			Field:    RuleField(strings.TrimSpace(record[0])),
+			Pattern:  strings.TrimSpace(record[1]),
+			Category: ProviderCategory(strings.TrimSpace(record[2])),
+			Weight:   weight,
+		}
+
+		switch rule.Field {
+		case RuleFieldASType, RuleFieldASName, RuleFieldNetname, RuleFieldDescription, RuleFieldOrg:
+		default:
+			return nil, fmt.Errorf("record %d: unknown field %q", i+2, rule.Field)
+		}
+
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("record %d: empty pattern", i+2)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// NewClassifier creates Classifier with the built-in rules and the rules from the params.
+func NewClassifier(params ClassifierParams) *Classifier {
+	var rules []ClassifierRule
+	if !params.NoBuiltinRules {
+		rules = BuiltinClassifierRules()
+	}
+
+	for _, override := range params.Rules {
+		replaced := false
+		for i, rule := range rules {
+			if rule.Field == override.Field && strings.EqualFold(rule.Pattern, override.Pattern) {
+				rules[i], replaced = override, true
+			}
+		}
+
+		if !replaced {
+			rules = append(rules, override)
+		}
+	}
+
+	c := &Classifier{}
+	for _, rule := range rules {
+		if rule.Weight == 0 {
+			continue
+		}
+
+		compiled := classifierRule{ClassifierRule: rule}
+		if rule.Field != RuleFieldASType {
+			compiled.re = regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(rule.Pattern) + `($|[^\pL\pN])`)
+		}
+
+		c.rules = append(c.rules, compiled)
+	}
+
+	return c
+}
+
+// Classify returns the category of the network the netblocks, e.g. returned by GetByIP, belong to.
+// Each netblock is matched against all rules, and the category with the highest score wins.
+func (c *Classifier) Classify(inetnums []Inetnum) *Classification {
+	classification := &Classification{Scores: make(map[ProviderCategory]int)}
+
+	var evidence []ClassificationEvidence
+	matched := make(map[int]bool)
+
+	for _, inetnum := range inetnums {
+		for i, rule := range c.rules {
+			for _, value := range rule.values(inetnum) {
+				if !rule.match(value) {
+					continue
+				}
+
+				evidence = append(evidence, ClassificationEvidence{
+					Rule:    rule.ClassifierRule,
+					Inetnum: inetnum.Inetnum,
+					Value:   value,
+				})
+
+				if !matched[i] {
+					matched[i] = true
+					classification.Scores[rule.Category] += rule.Weight
+				}
+			}
+		}
+	}
+
+	best := 0
+	for _, category := range scoredCategories(classification.Scores) {
+		if score := classification.Scores[category]; score > best {
+			classification.Category, best = category, score
+		}
+	}
+
+	for _, e := range evidence {
+		if e.Rule.Category == classification.Category && classification.Category != ProviderUnknown {
+			classification.Evidence = append(classification.Evidence, e)
+		}
+	}
+
+	return classification
+}
+
+// scoredCategories returns the scored categories, built-in ones first, then custom ones in alphabetical order.
+func scoredCategories(scores map[ProviderCategory]int) []ProviderCategory {
+	categories := append([]ProviderCategory(nil), providerCategories...)
+
+	var custom []ProviderCategory
+	for category := range scores {
+		switch category {
+		case ProviderHosting, ProviderISP, ProviderEducation, ProviderEnterprise:
+		default:
+			custom = append(custom, category)
+		}
+	}
+
+	sort.Slice(custom, func(i, j int) bool {
+		return custom[i] < custom[j]
+	})
+
+	return append(categories, custom...)
+}
+
+// values returns the values of the netblock field the rule matches.
+func (r classifierRule) values(inetnum Inetnum) []string {
+	switch r.Field {
+	case RuleFieldASType:
+		return []string{string(inetnum.AS.ASType())}
+	case RuleFieldASName:
+		return []string{inetnum.AS.Name}
+	case RuleFieldNetname:
+		return []string{inetnum.Netname}
+	case RuleFieldDescription:
+		return append(append([]string(nil), inetnum.Description...), inetnum.Remarks...)
+	case RuleFieldOrg:
+		return []string{inetnum.Org.Name}
+	default:
+		return nil
+	}
+}
+
+// match reports whether the rule matches the value.
+func (r classifierRule) match(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	if r.Field == RuleFieldASType {
+		return ParseASType(value) == ParseASType(r.Pattern)
+	}
+
+	return r.re.MatchString(value)
+}
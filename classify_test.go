@@ -0,0 +1,111 @@
+package ipnetblocks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestClassify tests the Classifier.Classify function.
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   ClassifierParams
+		inetnums []Inetnum
+		category ProviderCategory
+		evidence []string
+	}{
+		{
+			name: "hosting",
+			inetnums: []Inetnum{
+				{Inetnum: "5.9.0.0 - 5.9.255.255", Netname: "HETZNER-RZ-FSN-BLK", Org: Organization{Name: "Hetzner Online GmbH"}},
+				{Inetnum: "5.9.0.0 - 5.9.255.255", Parent: "5.9.0.0 - 5.9.255.255", AS: AS{ASN: 24940, Type: "Content"}},
+			},
+			category: ProviderHosting,
+			evidence: []string{"org:hetzner=Hetzner Online GmbH", "asType:Content=Content"},
+		},
+		{
+			name: "isp",
+			inetnums: []Inetnum{
+				{Inetnum: "24.0.0.0 - 24.15.255.255", Netname: "JUMPSTART-1", AS: AS{Type: "Cable/DSL/ISP"}},
+				{Inetnum: "24.0.0.0 - 24.0.255.255", Netname: "COMCAST-DYNAMIC-POOL", Description: []string{"Residential"}},
+			},
+			category: ProviderISP,
+			evidence: []string{
+				"asType:Cable/DSL/ISP=Cable/DSL/ISP",
+				"netname:dynamic=COMCAST-DYNAMIC-POOL",
+				"netname:pool=COMCAST-DYNAMIC-POOL",
+				"description:residential=Residential",
+			},
+		},
+		{
+			name: "education",
+			inetnums: []Inetnum{
+				{
+					Inetnum: "18.0.0.0 - 18.31.255.255",
+					Netname: "MIT",
+					Org:     Organization{Name: "Massachusetts Institute of Technology"},
+				},
+			},
+			category: ProviderEducation,
+			evidence: []string{"org:institute of technology=Massachusetts Institute of Technology"},
+		},
+		{
+			name: "local rules",
+			params: ClassifierParams{Rules: []ClassifierRule{
+				{Field: RuleFieldOrg, Pattern: "hetzner", Category: ProviderHosting},
+				{Field: RuleFieldNetname, Pattern: "RZ", Category: "datacenter", Weight: 5},
+			}},
+			inetnums: []Inetnum{
+				{Inetnum: "5.9.0.0 - 5.9.255.255", Netname: "HETZNER-RZ-FSN-BLK", Org: Organization{Name: "Hetzner Online GmbH"}},
+			},
+			category: "datacenter",
+			evidence: []string{"netname:RZ=HETZNER-RZ-FSN-BLK"},
+		},
+		{
+			name:     "unknown",
+			inetnums: []Inetnum{{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8"}},
+			category: ProviderUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewClassifier(tt.params).Classify(tt.inetnums)
+			if got.Category != tt.category {
+				t.Errorf("Classify() category = %q, want %q (scores %v)", got.Category, tt.category, got.Scores)
+			}
+
+			var evidence []string
+			for _, e := range got.Evidence {
+				evidence = append(evidence, string(e.Rule.Field)+":"+e.Rule.Pattern+"="+e.Value)
+			}
+
+			if !reflect.DeepEqual(evidence, tt.evidence) {
+				t.Errorf("Classify() evidence = %q, want %q", evidence, tt.evidence)
+			}
+		})
+	}
+}
+
+// TestParseClassifierRules tests the ParseClassifierRules function.
+func TestParseClassifierRules(t *testing.T) {
+	rules, err := ParseClassifierRules(strings.NewReader(
+		"Field,Pattern,Category,Weight\n# comment\nnetname,cdn,hosting,2\n"))
+	checkErr(t, err, "")
+
+	want := []ClassifierRule{{Field: RuleFieldNetname, Pattern: "cdn", Category: ProviderHosting, Weight: 2}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseClassifierRules() got = %v, want %v", rules, want)
+	}
+
+	_, err = ParseClassifierRules(strings.NewReader("Field,Pattern,Category,Weight\ncountry,US,isp,1\n"))
+	checkErr(t, err, `record 2: unknown field "country"`)
+
+	_, err = ParseClassifierRules(strings.NewReader("Field,Pattern,Category,Weight\nnetname,cdn,hosting,high\n"))
+	checkErr(t, err, `record 2: invalid weight "high"`)
+
+	if len(BuiltinClassifierRules()) == 0 {
+		t.Error("BuiltinClassifierRules() got no rules")
+	}
+}
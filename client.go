@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -27,6 +28,24 @@ type ClientParams struct {
 
 	// IPNetblocksBaseURL is the endpoint for 'IP Netblocks API' service
 	IPNetblocksBaseURL *url.URL
+
+	// Resolver is used to resolve host names passed to GetByHost
+	// If it's nil then value API client uses net.DefaultResolver
+	Resolver Resolver
+
+	// SpecialPurpose defines how GetByIP and GetByCIDR handle private, loopback, documentation and other
+	// special-purpose addresses
+	// If it's zero then requests are sent to the API as usual
+	SpecialPurpose SpecialPurposeMode
+
+	// Input defines how GetByIP and GetByCIDR normalize and validate their input
+	Input InputParams
+}
+
+// Resolver resolves host names to IP addresses. It's implemented by net.Resolver.
+type Resolver interface {
+	// LookupIPAddr looks up host and returns a slice of its IPv4 and IPv6 addresses.
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
 }
 
 // NewBasicClient creates Client with recommended parameters.
@@ -51,26 +70,46 @@ func NewClient(apiKey string, params ClientParams) *Client {
 		httpClient = params.HTTPClient
 	}
 
+	var resolver Resolver = net.DefaultResolver
+	if params.Resolver != nil {
+		resolver = params.Resolver
+	}
+
 	client := &Client{
-		client:    httpClient,
-		userAgent: userAgent,
-		apiKey:    apiKey,
+		client:         httpClient,
+		resolver:       resolver,
+		specialPurpose: params.SpecialPurpose,
+		input:          params.Input,
+		userAgent:      userAgent,
+		apiKey:         apiKey,
 	}
 
-	client.IPNetblocks = &ipNetblocksServiceOp{client: client, baseURL: apiBaseURL}
+	service := &ipNetblocksServiceOp{client: client, baseURL: apiBaseURL}
+	client.IPNetblocks = service
+	client.RangeResolver = service
+	client.AbuseResolver = service
 
 	return client
 }
 
 // Client is the client for IP Netblocks API services.
 type Client struct {
-	client *http.Client
+	client         *http.Client
+	resolver       Resolver
+	specialPurpose SpecialPurposeMode
+	input          InputParams
 
 	userAgent string
 	apiKey    string
 
 	// IPNetblocks is an interface for IP Netblocks API
 	IPNetblocks
+
+	// RangeResolver is an interface for lookups by IP ranges and host names
+	RangeResolver
+
+	// AbuseResolver is an interface for abuse contact lookups
+	AbuseResolver
 }
 
 // NewRequest creates a basic API request.
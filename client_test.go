@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -203,7 +204,7 @@ func TestIPNetblocksGetByIP(t *testing.T) {
 				},
 			},
 			want:    false,
-			wantErr: `API error: [499] Test error message.`,
+			wantErr: `invalid argument: "?080808" is invalid IP address`,
 		},
 		{
 			name: "invalid argument2",
@@ -1354,3 +1355,199 @@ func TestIPNetblocksGetRawByOrg(t *testing.T) {
 		})
 	}
 }
+
+// pagedServer is the sample of the IP Netblocks API server which responds according to the "ip", "mask" and
+// "from" query parameters. Pages are keyed as "ip/mask" or "ip/mask|from".
+func pagedServer(pages map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+
+		key := q.Get("ip") + "/" + q.Get("mask")
+		if from := q.Get("from"); from != "" {
+			key += "|" + from
+		}
+
+		response, ok := pages[key]
+		if !ok {
+			response = `{"search":"` + key + `","result":{"count":0,"limit":100,"from":null,"next":null,"inetnums":[]}}`
+		}
+
+		_, err := w.Write([]byte(response))
+		if err != nil {
+			panic(err)
+		}
+	}))
+
+	return server
+}
+
+// TestIPNetblocksGetByRange tests the GetByRange function.
+func TestIPNetblocksGetByRange(t *testing.T) {
+	ctx := context.Background()
+
+	server := pagedServer(map[string]string{
+		"10.0.0.0/31": `{"search":"10.0.0.0/31","result":{"count":1,"limit":1,"from":null,"next":"10.0.0.0-10.255.255.255",
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"}]}}`,
+		"10.0.0.0/31|10.0.0.0-10.255.255.255": `{"search":"10.0.0.0/31","result":{"count":1,"limit":1,
+"from":"10.0.0.0-10.255.255.255","next":null,"inetnums":[{"inetnum":"10.0.0.0 - 10.0.0.1","source":"RIPE"}]}}`,
+		"10.0.0.2/32": `{"search":"10.0.0.2/32","result":{"count":2,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"},{"inetnum":"10.0.0.2 - 10.0.0.2","source":"RIPE"}]}}`,
+	})
+	defer server.Close()
+
+	from := "10.0.0.0-10.255.255.255"
+
+	tests := []struct {
+		name    string
+		ipRange string
+		opts    []Option
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "merged and deduplicated",
+			ipRange: "10.0.0.0 - 10.0.0.2",
+			want:    []string{"10.0.0.0 - 10.255.255.255", "10.0.0.0 - 10.0.0.1", "10.0.0.2 - 10.0.0.2"},
+		},
+		{
+			name:    "caller from ignored",
+			ipRange: "10.0.0.0 - 10.0.0.2",
+			opts:    []Option{OptionFrom(&from)},
+			want:    []string{"10.0.0.0 - 10.255.255.255", "10.0.0.0 - 10.0.0.1", "10.0.0.2 - 10.0.0.2"},
+		},
+		{
+			name:    "empty result",
+			ipRange: "192.168.0.0 - 192.168.0.255",
+			want:    nil,
+		},
+		{
+			name:    "invalid argument1",
+			ipRange: "",
+			wantErr: `invalid argument: "ipRange" can not be empty`,
+		},
+		{
+			name:    "invalid argument2",
+			ipRange: "10.0.0.2 - 10.0.0.0",
+			wantErr: `invalid argument: "10.0.0.2 - 10.0.0.0" is invalid IP range`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newAPI(server, "/")
+
+			gotRec, err := api.GetByRange(ctx, tt.ipRange, append([]Option{OptionLimit(100)}, tt.opts...)...)
+			if (err != nil || tt.wantErr != "") && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("IPNetblocks.GetByRange() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if tt.wantErr != "" {
+				return
+			}
+
+			var got []string
+			for _, inetnum := range gotRec.Result.Inetnums {
+				got = append(got, inetnum.Inetnum)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) || gotRec.Result.Count != len(tt.want) {
+				t.Errorf("IPNetblocks.GetByRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeResolver is the sample of the DNS resolver for testing.
+type fakeResolver map[string][]net.IPAddr
+
+// LookupIPAddr returns addresses stored for the host.
+func (r fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+// TestIPNetblocksGetByHost tests the GetByHost function.
+func TestIPNetblocksGetByHost(t *testing.T) {
+	ctx := context.Background()
+
+	server := pagedServer(map[string]string{
+		"8.8.8.8/": `{"search":"8.8.8.8","result":{"count":1,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"8.8.8.0 - 8.8.8.255","source":"ARIN"}]}}`,
+		"2001:4860:4860::8888/": `{"search":"2001:4860:4860::8888","result":{"count":1,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff","source":"ARIN"}]}}`,
+	})
+	defer server.Close()
+
+	resolver := fakeResolver{
+		"dns.google": {
+			{IP: net.ParseIP("8.8.8.8")},
+			{IP: net.ParseIP("2001:4860:4860::8888")},
+			{IP: net.ParseIP("8.8.8.8")},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		host    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "resolved host",
+			host: "dns.google",
+			want: map[string]string{
+				"8.8.8.8":              "8.8.8.0 - 8.8.8.255",
+				"2001:4860:4860::8888": "2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff",
+			},
+		},
+		{
+			name:    "unknown host",
+			host:    "example.invalid",
+			wantErr: "cannot resolve host: lookup example.invalid: no such host",
+		},
+		{
+			name:    "invalid argument",
+			host:    "",
+			wantErr: `invalid argument: "host" can not be empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			api := NewClient(apiKey, ClientParams{
+				HTTPClient:         server.Client(),
+				IPNetblocksBaseURL: apiURL,
+				Resolver:           resolver,
+			})
+
+			gotRec, err := api.GetByHost(ctx, tt.host)
+			if (err != nil || tt.wantErr != "") && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("IPNetblocks.GetByHost() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if tt.wantErr != "" {
+				return
+			}
+
+			got := make(map[string]string, len(gotRec))
+			for ip, rec := range gotRec {
+				got[ip] = rec.Result.Inetnums[0].Inetnum
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IPNetblocks.GetByHost() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
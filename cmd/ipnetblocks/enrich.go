@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/enrich"
+)
+
+// enricher adds netblock fields to logs.
+type enricher interface {
+	Enrich(ctx context.Context, r io.Reader, w io.Writer) (*enrich.Stats, error)
+}
+
+// runEnrich adds netblock fields to logs read from files or the standard input.
+func runEnrich(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("enrich", flag.ContinueOnError)
+	input := flags.String("input", "access", "log type: access (nginx or Apache), zeek or suricata")
+	asJSON := flags.Bool("json", false, "write access log lines as JSON objects")
+	fields := flags.String("fields", "asn,org,country,netname",
+		"comma-separated fields to add: asn, as_name, org, country, netname, netblock")
+	cacheSize := flags.Int("cache", 10000, "maximum number of cached netblocks")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ipnetblocks enrich [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	key, err := apiKey()
+	if err != nil {
+		return err
+	}
+
+	fieldList, err := parseFields(*fields)
+	if err != nil {
+		return err
+	}
+
+	// Private, loopback and other special-purpose addresses are common in logs and are answered locally.
+	client := ipnetblocks.NewClient(key, ipnetblocks.ClientParams{SpecialPurpose: ipnetblocks.SpecialPurposeLocal})
+	resolver := enrich.NewResolver(client, enrich.ResolverParams{CacheSize: *cacheSize})
+
+	var e enricher
+	switch *input {
+	case "access":
+		params := enrich.AccessLogParams{Format: enrich.FormatLog, Fields: fieldList}
+		if *asJSON {
+			params.Format = enrich.FormatJSON
+		}
+
+		e = enrich.NewAccessLog(resolver, params)
+	case "zeek":
+		e = enrich.NewZeek(resolver, enrich.ZeekParams{Fields: fieldList})
+	case "suricata":
+		e = enrich.NewSuricata(resolver, enrich.SuricataParams{Fields: fieldList})
+	default:
+		fmt.Fprintf(flags.Output(), "unknown input %q\n", *input)
+		flags.Usage()
+		return errUsage
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	for _, path := range paths {
+		if err = enrichFile(ctx, e, path); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d requests, %d cache hits\n", resolver.Requests, resolver.Hits)
+
+	return nil
+}
+
+// enrichFile enriches the file, or the standard input for "-", to the standard output.
+func enrichFile(ctx context.Context, enricher enricher, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	stats, err := enricher.Enrich(ctx, r, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %d lines, %d enriched, %d unparsed\n", path, stats.Lines, stats.Enriched, stats.Unparsed)
+
+	return nil
+}
+
+// parseFields parses the comma-separated list of fields.
+func parseFields(s string) ([]enrich.Field, error) {
+	var fields []enrich.Field
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		field, err := enrich.ParseField(name)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/whois-api-llc/ip-netblocks-go/enrich"
+)
+
+// TestRunEnrich tests flags and errors of the runEnrich function.
+func TestRunEnrich(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "access.log")
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		args    []string
+		wantErr error
+		err     string
+	}{
+		{name: "help", args: []string{"-h"}, wantErr: flag.ErrHelp},
+		{name: "unknown flag", args: []string{"-unknown"}, wantErr: errUsage},
+		{name: "invalid cache", args: []string{"-cache", "many"}, wantErr: errUsage},
+		{name: "no api key", args: []string{missing}, err: "IPNETBLOCKS_API_KEY is not set"},
+		{
+			name:   "unknown field",
+			apiKey: "key",
+			args:   []string{"-fields", "asn,city", missing},
+			err:    `invalid argument: "city" is unknown field`,
+		},
+		{name: "unknown input", apiKey: "key", args: []string{"-input", "syslog", missing}, wantErr: errUsage},
+		{name: "no file", apiKey: "key", args: []string{"-input", "zeek", missing}, wantErr: os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IPNETBLOCKS_API_KEY", tt.apiKey)
+
+			err := runEnrich(context.Background(), tt.args)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("runEnrich() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			case err == nil || err.Error() != tt.err:
+				t.Errorf("runEnrich() error = %v, wantErr %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestParseFields tests the parseFields function.
+func TestParseFields(t *testing.T) {
+	got, err := parseFields(" asn, netblock,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []enrich.Field{enrich.FieldASN, enrich.FieldNetblock}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFields() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/whois-api-llc/ip-netblocks-go/snapshot"
+)
+
+// runHistory prints the ownership timeline of an IP address or prefix from the snapshot store.
+func runHistory(_ context.Context, args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	storePath := flags.String("store", "snapshots", "path to the snapshot store")
+	asJSON := flags.Bool("json", false, "print the timeline as JSON")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ipnetblocks history [flags] <ip|cidr>")
+		flags.PrintDefaults()
+	}
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	prefix, err := parsePrefix(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	history, err := snapshot.NewStore(*storePath).History(prefix)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return history.WriteJSON(os.Stdout)
+	}
+
+	return history.WriteText(os.Stdout)
+}
+
+// parsePrefix parses the IP address or CIDR.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
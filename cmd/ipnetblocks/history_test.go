@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/snapshot"
+)
+
+// TestRunHistory tests the runHistory function.
+func TestRunHistory(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "snapshots")
+
+	_, err := snapshot.NewStore(store).Write(time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), []ipnetblocks.Inetnum{
+		{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8", Source: "ARIN"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+		err     string
+	}{
+		{name: "ip", args: []string{"-store", store, "8.8.8.8"}},
+		{name: "cidr as json", args: []string{"-store", store, "-json", "8.8.0.0/16"}},
+		{name: "help", args: []string{"-h"}, wantErr: flag.ErrHelp},
+		{name: "unknown flag", args: []string{"-unknown", "8.8.8.8"}, wantErr: errUsage},
+		{name: "no target", args: []string{"-store", store}, wantErr: errUsage},
+		{name: "several targets", args: []string{"8.8.8.8", "8.8.4.4"}, wantErr: errUsage},
+		{name: "invalid target", args: []string{"8.8.8"}, err: `ParseAddr("8.8.8"): IPv4 address too short`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runHistory(context.Background(), tt.args)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("runHistory() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			case tt.err != "":
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("runHistory() error = %v, wantErr %v", err, tt.err)
+				}
+			case err != nil:
+				t.Errorf("runHistory() error = %v", err)
+			}
+		})
+	}
+}
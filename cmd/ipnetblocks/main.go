@@ -0,0 +1,98 @@
+// Command ipnetblocks runs tools built on the IP Netblocks API client.
+//
+// Usage:
+//
+//	ipnetblocks <command> [flags]
+//
+// The API key is taken from the IPNETBLOCKS_API_KEY environment variable. Run a command with -h to see its flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands is the list of subcommands.
+var commands = []command{
+	{name: "monitor", summary: "watch ASNs and organizations and post changes to webhooks", run: runMonitor},
+	{name: "history", summary: "print the ownership timeline of an IP or CIDR from stored snapshots", run: runHistory},
+	{name: "enrich", summary: "add netblock fields to access, Zeek or Suricata logs", run: runEnrich},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+
+		err := cmd.run(ctx, os.Args[2:])
+		switch {
+		case err == nil || ctx.Err() != nil || errors.Is(err, flag.ErrHelp):
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		default:
+			fmt.Fprintf(os.Stderr, "ipnetblocks %s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+// errUsage is returned by subcommands for invalid command lines, after the error and the usage were printed.
+var errUsage = errors.New("invalid command line")
+
+// parseFlags parses the arguments of the subcommand. It returns flag.ErrHelp when help was requested, and errUsage
+// for invalid flags.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+
+	return err
+}
+
+// usage prints the list of subcommands.
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: ipnetblocks <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// apiKey returns the API key from the environment.
+func apiKey() (string, error) {
+	key := os.Getenv("IPNETBLOCKS_API_KEY")
+	if key == "" {
+		return "", fmt.Errorf("IPNETBLOCKS_API_KEY is not set")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/monitor"
+)
+
+// runMonitor runs the watchlist monitor until interrupted.
+func runMonitor(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	configPath := flags.String("config", "monitor.json", "path to the JSON config with targets and webhooks")
+	once := flags.Bool("once", false, "poll every target once and exit")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	key, err := apiKey()
+	if err != nil {
+		return err
+	}
+
+	config, err := monitor.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	m, err := monitor.New(ipnetblocks.NewBasicClient(key), *config, monitor.Params{})
+	if err != nil {
+		return err
+	}
+
+	if !*once {
+		return m.Run(ctx)
+	}
+
+	for _, target := range config.Targets {
+		if _, err = m.Poll(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunMonitor tests flags and errors of the runMonitor function.
+func TestRunMonitor(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "monitor.json")
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		args    []string
+		wantErr error
+		err     string
+	}{
+		{name: "help", args: []string{"-h"}, wantErr: flag.ErrHelp},
+		{name: "unknown flag", args: []string{"-unknown"}, wantErr: errUsage},
+		{name: "invalid once", args: []string{"-once=maybe"}, wantErr: errUsage},
+		{name: "no api key", args: []string{"-once"}, err: "IPNETBLOCKS_API_KEY is not set"},
+		{name: "no config", apiKey: "key", args: []string{"-config", missing, "-once"}, wantErr: os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IPNETBLOCKS_API_KEY", tt.apiKey)
+
+			err := runMonitor(context.Background(), tt.args)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("runMonitor() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			case err == nil || err.Error() != tt.err:
+				t.Errorf("runMonitor() error = %v, wantErr %v", err, tt.err)
+			}
+		})
+	}
+}
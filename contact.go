@@ -0,0 +1,162 @@
+package ipnetblocks
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ContactKind is the type of contact object.
+type ContactKind string
+
+const (
+	// ContactKindPerson is the contact of a person.
+	ContactKindPerson ContactKind = "person"
+
+	// ContactKindRole is the contact of a role, e.g. a team or a department.
+	ContactKindRole ContactKind = "role"
+
+	// ContactKindUnknown is the contact with neither Person nor Role set.
+	ContactKindUnknown ContactKind = ""
+)
+
+// Kind returns the type of the contact object.
+func (c Contact) Kind() ContactKind {
+	switch {
+	case c.Person != "":
+		return ContactKindPerson
+	case c.Role != "":
+		return ContactKindRole
+	default:
+		return ContactKindUnknown
+	}
+}
+
+// Name returns the name of the contact person or role.
+func (c Contact) Name() string {
+	if c.Person != "" {
+		return c.Person
+	}
+
+	return c.Role
+}
+
+// Emails returns the valid lowercased email addresses of the contact without duplicates.
+func (c Contact) Emails() []string {
+	return uniqueEmails(extractEmails(c.Email))
+}
+
+// Phones returns the phone numbers of the contact in the E.164 format without duplicates.
+// Numbers without the international prefix are skipped.
+func (c Contact) Phones() []string {
+	return parsePhones(c.Phone)
+}
+
+// Emails returns the valid lowercased email addresses of the organization without duplicates.
+func (o Organization) Emails() []string {
+	return uniqueEmails(extractEmails(o.Email))
+}
+
+// Phones returns the phone numbers of the organization in the E.164 format without duplicates.
+// Numbers without the international prefix are skipped.
+func (o Organization) Phones() []string {
+	return parsePhones(o.Phone)
+}
+
+// Contacts returns abuse, administrative and technical contacts of the netblock, in that order.
+// Contacts referenced several times are included only once.
+func (i Inetnum) Contacts() []Contact {
+	contacts := make([]Contact, 0, len(i.AbuseContact)+len(i.AdminContact)+len(i.TechContact))
+	contacts = append(contacts, i.AbuseContact...)
+	contacts = append(contacts, i.AdminContact...)
+	contacts = append(contacts, i.TechContact...)
+
+	return UniqueContacts(contacts)
+}
+
+// UniqueContacts returns the contacts without duplicates, in the original order. Contacts are compared by ID
+// case-insensitively, contacts without ID are compared by all fields. The first of duplicates is kept.
+func UniqueContacts(contacts []Contact) []Contact {
+	seen := make(map[string]bool, len(contacts))
+
+	var unique []Contact
+	for _, c := range contacts {
+		key := "id:" + strings.ToUpper(c.ID)
+		if c.ID == "" {
+			key = strings.Join(append([]string{c.Person, c.Role, c.Email, c.Phone, c.Country, c.City}, c.Address...),
+				"\x00")
+		}
+
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, c)
+		}
+	}
+
+	return unique
+}
+
+// phoneExtensionRegexp matches the trailing extension of a phone number: "ext. 12", "extension 12", "x12"
+// or ";ext=12".
+var phoneExtensionRegexp = regexp.MustCompile(`(?i)(?:\s*;\s*ext\s*=|\s*ext(?:ension)?\.?|\s*x)\s*\d+$`)
+
+// NormalizePhone returns the phone number in the E.164 format, e.g. "+18005551234". It accepts common notations
+// like "+1 (800) 555-1234", "+1-800-555-1234 ext. 12" and "00 1 800 555 1234", and ignores extensions.
+// It reports false if the number has no international prefix or has the wrong number of digits.
+func NormalizePhone(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 4 && strings.EqualFold(s[:4], "tel:") {
+		s = s[4:]
+	}
+
+	s = phoneExtensionRegexp.ReplaceAllString(s, "")
+
+	var digits strings.Builder
+	international := false
+
+	for i, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", false
+		}
+	}
+
+	number := digits.String()
+	if !international {
+		if !strings.HasPrefix(number, "00") {
+			return "", false
+		}
+		number = number[2:]
+	}
+
+	// E.164 numbers have up to 15 digits and never start with zero.
+	if len(number) < 7 || len(number) > 15 || number[0] == '0' {
+		return "", false
+	}
+
+	return "+" + number, true
+}
+
+// parsePhones returns normalized unique phone numbers from the list separated by newlines or commas.
+func parsePhones(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\n' || r == ','
+	})
+
+	seen := make(map[string]bool, len(fields))
+
+	var phones []string
+	for _, field := range fields {
+		phone, ok := NormalizePhone(field)
+		if ok && !seen[phone] {
+			seen[phone] = true
+			phones = append(phones, phone)
+		}
+	}
+
+	return phones
+}
@@ -0,0 +1,103 @@
+package ipnetblocks
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestContactKind tests the Contact.Kind function.
+func TestContactKind(t *testing.T) {
+	tests := []struct {
+		contact Contact
+		kind    ContactKind
+		name    string
+	}{
+		{Contact{Person: "John Doe"}, ContactKindPerson, "John Doe"},
+		{Contact{Role: "Google LLC NOC"}, ContactKindRole, "Google LLC NOC"},
+		{Contact{ID: "ABUSE5250-ARIN"}, ContactKindUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.contact.Kind(); got != tt.kind {
+			t.Errorf("Kind() got = %q, want %q", got, tt.kind)
+		}
+
+		if got := tt.contact.Name(); got != tt.name {
+			t.Errorf("Name() got = %q, want %q", got, tt.name)
+		}
+	}
+}
+
+// TestOrganizationEmails tests the Organization.Emails function.
+func TestOrganizationEmails(t *testing.T) {
+	org := Organization{Email: "arin-contact@google.com\nnetwork-abuse@google.com\nARIN-contact@Google.com\nnot an email"}
+
+	want := []string{"arin-contact@google.com", "network-abuse@google.com"}
+	if got := org.Emails(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Emails() got = %v, want %v", got, want)
+	}
+}
+
+// TestNormalizePhone tests the NormalizePhone function.
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+		ok    bool
+	}{
+		{"+1-650-253-0000", "+16502530000", true},
+		{"+1 (650) 253-0000", "+16502530000", true},
+		{"+31 20 535 4444 ext. 12", "+31205354444", true},
+		{"+49.69.27235.0", "+4969272350", true},
+		{"tel:+61-7-3858-3100", "+61738583100", true},
+		{"Tel:+61-7-3858-3100", "+61738583100", true},
+		{"tel:+1-650-253-0000;ext=12", "+16502530000", true},
+		{"+1 650 253 0000 x12", "+16502530000", true},
+		{"+1-650-253-0000x 12", "+16502530000", true},
+		{"+1 650 253 0000 Extension 12", "+16502530000", true},
+		{"+1 650 253 0000 EXT12", "+16502530000", true},
+		{"+1 650 253 0000 ext", "", false},
+		{"+1 650 253 0000 (exchange)", "", false},
+		{"+1 650 253 0000 fax", "", false},
+		{"+1 650 253 0000; fax 12", "", false},
+		{"00 7 495 737 9292", "+74957379292", true},
+		{"650-253-0000", "", false},
+		{"+0 123 456 789", "", false},
+		{"+1 650 253 0000 0000 0000", "", false},
+		{"+1 650 CALL NOW", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got, ok := NormalizePhone(tt.phone)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("NormalizePhone() got = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	contact := Contact{Phone: "+1-650-253-0000\n+1 (650) 253-0000, 650-253-0001,+1-650-253-0002"}
+
+	want := []string{"+16502530000", "+16502530002"}
+	if got := contact.Phones(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Phones() got = %v, want %v", got, want)
+	}
+}
+
+// TestInetnumContacts tests the Inetnum.Contacts function.
+func TestInetnumContacts(t *testing.T) {
+	abuse := Contact{ID: "ABUSE5250-ARIN", Role: "Abuse", Email: "network-abuse@google.com"}
+	noc := Contact{ID: "ZG39-ARIN", Role: "Google LLC", Email: "arin-contact@google.com"}
+
+	inetnum := Inetnum{
+		AbuseContact: []Contact{abuse},
+		AdminContact: []Contact{noc, {Person: "John Doe"}},
+		TechContact:  []Contact{{ID: "zg39-arin", Role: "Google LLC"}, {Person: "John Doe"}, {Person: "Jane Doe"}},
+	}
+
+	want := []Contact{abuse, noc, {Person: "John Doe"}, {Person: "Jane Doe"}}
+	if got := inetnum.Contacts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Contacts() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package ipnetblocks
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"strings"
+)
+
+// Country is an entry of the ISO 3166-1 country table.
+type Country struct {
+	// Code is the two letters country code.
+	Code string
+
+	// Alpha3 is the three letters country code.
+	Alpha3 string
+
+	// Name is the short name of the country.
+	Name string
+
+	// Region is the UN M49 region, e.g. "Europe". Empty for Antarctica.
+	Region string
+
+	// SubRegion is the UN M49 sub-region, e.g. "Western Europe". Empty for Antarctica.
+	SubRegion string
+}
+
+//go:embed data/iso3166.csv
+var iso3166CSV []byte
+
+// countryList is the parsed country table ordered by the two letters code.
+var countryList = mustParseCountries(iso3166CSV)
+
+// countries is the country table indexed by two and three letters codes.
+var countries = indexCountries(countryList)
+
+// mustParseCountries parses the embedded country table.
+func mustParseCountries(raw []byte) []Country {
+	records, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	list := make([]Country, 0, len(records))
+	for _, record := range records[1:] {
+		list = append(list, Country{
+			Code:      record[0],
+			Alpha3:    record[1],
+			Name:      record[2],
+			Region:    record[3],
+			SubRegion: record[4],
+		})
+	}
+
+	return list
+}
+
+// indexCountries returns the countries indexed by two and three letters codes.
+func indexCountries(list []Country) map[string]Country {
+	index := make(map[string]Country, 2*len(list))
+	for _, country := range list {
+		index[country.Code] = country
+		index[country.Alpha3] = country
+	}
+
+	return index
+}
+
+// countryAliases maps the codes registries commonly use instead of ISO 3166 ones to the ISO 3166 codes.
+var countryAliases = map[string]string{
+	"UK": "GB",
+}
+
+// countryKey returns the upper-cased code without surrounding spaces, with aliases replaced.
+func countryKey(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if alias, ok := countryAliases[code]; ok {
+		return alias
+	}
+
+	return code
+}
+
+// LookupCountry returns the country by its two or three letters code, ignoring case and surrounding spaces.
+// Aliases commonly used by registries, e.g. "UK" for the United Kingdom, are accepted.
+func LookupCountry(code string) (Country, bool) {
+	country, ok := countries[countryKey(code)]
+
+	return country, ok
+}
+
+// IsValidCountry reports whether the code is a two letters country code from ISO 3166, ignoring case and
+// surrounding spaces. Aliases are accepted as in LookupCountry, e.g. "UK" is valid.
+func IsValidCountry(code string) bool {
+	key := countryKey(code)
+	country, ok := countries[key]
+
+	return ok && country.Code == key
+}
+
+// Countries returns all countries ordered by the two letters code.
+func Countries() []Country {
+	return append([]Country(nil), countryList...)
+}
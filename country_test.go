@@ -0,0 +1,42 @@
+package ipnetblocks
+
+import (
+	"testing"
+)
+
+// TestLookupCountry tests the LookupCountry function.
+func TestLookupCountry(t *testing.T) {
+	tests := []struct {
+		code   string
+		name   string
+		region string
+		ok     bool
+	}{
+		{"US", "United States of America", "Americas", true},
+		{" de ", "Germany", "Europe", true},
+		{"JPN", "Japan", "Asia", true},
+		{"UK", "United Kingdom of Great Britain and Northern Ireland", "Europe", true},
+		{"AQ", "Antarctica", "", true},
+		{"EU", "", "", false},
+		{"ZZ", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			country, ok := LookupCountry(tt.code)
+			if ok != tt.ok || country.Name != tt.name || country.Region != tt.region {
+				t.Errorf("LookupCountry() got = %+v, %v", country, ok)
+			}
+		})
+	}
+
+	if !IsValidCountry("nl") || !IsValidCountry(" uk ") || IsValidCountry("NLD") || IsValidCountry("EU") {
+		t.Error("IsValidCountry() accepted an invalid code or rejected a valid one")
+	}
+
+	list := Countries()
+	if len(list) != 249 || list[0].Code != "AD" || list[len(list)-1].Code != "ZW" {
+		t.Errorf("Countries() got %d countries", len(list))
+	}
+}
@@ -0,0 +1,397 @@
+package enrich
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Format is the output format of enriched access logs.
+type Format string
+
+const (
+	// FormatLog appends fields to the lines as quoted strings, with "-" for missing values.
+	FormatLog Format = "log"
+
+	// FormatJSON writes lines as JSON objects with keys named after nginx variables and the added fields.
+	FormatJSON Format = "json"
+)
+
+// accessLogRegexp matches lines in the common and combined log formats. Extra fields are left in the rest.
+var accessLogRegexp = regexp.MustCompile(
+	`^(\S+) (\S+) (\S+) \[([^\]]*)\] "((?:[^"\\]|\\.)*)" (\d{3}|-) (\d+|-)` +
+		`(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+// accessLogKeys are the JSON keys of the submatches of accessLogRegexp.
+var accessLogKeys = []string{
+	"remote_addr", "ident", "remote_user", "time_local", "request", "status", "body_bytes_sent",
+	"http_referer", "http_user_agent",
+}
+
+// Stats is the statistics of an enrichment run.
+type Stats struct {
+	// Lines is the number of lines read.
+	Lines int
+
+	// Enriched is the number of lines with at least one address found in netblocks.
+	Enriched int
+
+	// Unparsed is the number of lines which didn't match the expected format or were longer than MaxLineLength,
+	// and were written unchanged.
+	Unparsed int
+}
+
+// AccessLogParams is used to create AccessLog. None of parameters are mandatory.
+type AccessLogParams struct {
+	// Format is the output format. Default: FormatLog.
+	Format Format
+
+	// Fields is the list of added fields. Default: DefaultFields.
+	Fields []Field
+}
+
+// AccessLog enriches nginx and Apache access logs in the common or combined format with netblock fields
+// of client addresses.
+type AccessLog struct {
+	resolver *Resolver
+	format   Format
+	fields   []Field
+}
+
+// NewAccessLog creates AccessLog.
+func NewAccessLog(resolver *Resolver, params AccessLogParams) *AccessLog {
+	a := &AccessLog{resolver: resolver, format: params.Format, fields: params.Fields}
+
+	if a.format == "" {
+		a.format = FormatLog
+	}
+
+	if a.fields == nil {
+		a.fields = DefaultFields
+	}
+
+	return a
+}
+
+// Enrich reads the log line by line and writes the enriched lines, so that memory use doesn't depend on
+// the log size. Lines in other formats are written unchanged, wrapped into the "line" key of JSON objects
+// in FormatJSON. It stops at the first failed lookup.
+func (a *AccessLog) Enrich(ctx context.Context, r io.Reader, w io.Writer) (*Stats, error) {
+	if a.format != FormatLog && a.format != FormatJSON {
+		return nil, &ipnetblocks.ArgError{Name: "Format", Message: "must be log or json"}
+	}
+
+	stats := &Stats{}
+	err := eachLine(r, w, stats, func(line string, out *bufio.Writer) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		m := accessLogRegexp.FindStringSubmatchIndex(line)
+		if m == nil {
+			stats.Unparsed++
+			return a.writeUnparsed(out, line)
+		}
+
+		var netblock *Netblock
+		if ip, err := netip.ParseAddr(line[m[2]:m[3]]); err == nil {
+			if netblock, err = a.resolver.Lookup(ctx, ip); err != nil {
+				return fmt.Errorf("line %d: %w", stats.Lines, err)
+			}
+		}
+
+		if netblock != nil {
+			stats.Enriched++
+		}
+
+		if a.format == FormatJSON {
+			return a.writeJSON(out, line, m, netblock)
+		}
+
+		return a.writeLog(out, line, netblock)
+	})
+
+	return stats, err
+}
+
+// writeUnparsed writes the line which didn't match the format.
+func (a *AccessLog) writeUnparsed(w *bufio.Writer, line string) error {
+	if a.format == FormatJSON {
+		return writeJSONLine(w, object{{"line", line}})
+	}
+
+	_, err := w.WriteString(line)
+
+	return err
+}
+
+// writeLog writes the line with fields appended as quoted strings.
+func (a *AccessLog) writeLog(w *bufio.Writer, line string, netblock *Netblock) error {
+	var b strings.Builder
+	b.WriteString(line)
+
+	for _, field := range a.fields {
+		value := netblock.Value(field)
+		if value == "" {
+			value = "-"
+		}
+
+		b.WriteString(` "`)
+		b.WriteString(quoteReplacer.Replace(value))
+		b.WriteString(`"`)
+	}
+
+	_, err := w.WriteString(b.String())
+
+	return err
+}
+
+// writeJSON writes the line as the JSON object.
+func (a *AccessLog) writeJSON(w *bufio.Writer, line string, m []int, netblock *Netblock) error {
+	var obj object
+
+	for i, key := range accessLogKeys {
+		start, end := m[2*i+2], m[2*i+3]
+		if start < 0 {
+			continue
+		}
+
+		value := line[start:end]
+		switch {
+		case key == "status" || key == "body_bytes_sent":
+			n, _ := strconv.Atoi(value)
+			obj = append(obj, member{key, n})
+		case value == "-":
+			continue
+		case key == "request" || key == "http_referer" || key == "http_user_agent":
+			obj = append(obj, member{key, unquote(value)})
+		default:
+			obj = append(obj, member{key, value})
+		}
+	}
+
+	if rest := strings.TrimSpace(line[m[1]:]); rest != "" {
+		obj = append(obj, member{"extra", rest})
+	}
+
+	obj = appendFields(obj, "", a.fields, netblock)
+
+	return writeJSONLine(w, obj)
+}
+
+// appendFields appends the fields of the netblock which have values, with keys prefixed by the namespace.
+// The ASN is added as a number.
+func appendFields(obj object, namespace string, fields []Field, netblock *Netblock) object {
+	for _, field := range fields {
+		value := netblock.Value(field)
+		if value == "" {
+			continue
+		}
+
+		if field == FieldASN {
+			obj = append(obj, member{namespace + string(field), netblock.ASN})
+		} else {
+			obj = append(obj, member{namespace + string(field), value})
+		}
+	}
+
+	return obj
+}
+
+// quoteReplacer escapes quotes and backslashes of quoted log fields.
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// unquote unescapes the quoted log field. Apache escapes quotes and backslashes with backslashes, whitespace
+// in the C notation and other special characters as "\xHH", while nginx escapes all of them as "\xHH", e.g.
+// "\x22" for a quote. Unknown escapes are kept as is.
+func unquote(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch c := s[i+1]; c {
+		case '"', '\\':
+			b.WriteByte(c)
+			i++
+		case 'n':
+			b.WriteByte('\n')
+			i++
+		case 'r':
+			b.WriteByte('\r')
+			i++
+		case 't':
+			b.WriteByte('\t')
+			i++
+		case 'x', 'X':
+			if i+3 < len(s) {
+				if n, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+					b.WriteByte(byte(n))
+					i += 3
+					continue
+				}
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// member is a key and value of a JSON object.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is a JSON object which keeps the order of its members.
+type object []member
+
+// MarshalJSON returns the JSON encoding of the object.
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// writeJSONLine writes the object as a line of JSON.
+func writeJSONLine(w *bufio.Writer, obj object) error {
+	b, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// MaxLineLength is the maximum length of a log line in bytes without its terminator. Longer lines are written
+// unchanged and counted as unparsed.
+const MaxLineLength = 1 << 20
+
+// eachLine calls fn for every line of r without its line terminator, then writes the terminator after
+// what fn has written. Lines longer than MaxLineLength are copied without calling fn. Lines are counted
+// in stats.
+func eachLine(r io.Reader, w io.Writer, stats *Stats, fn func(line string, out *bufio.Writer) error) error {
+	in := bufio.NewReaderSize(r, MaxLineLength+len("\r\n"))
+	out := bufio.NewWriter(w)
+
+	for {
+		chunk, readErr := in.ReadSlice('\n')
+		if readErr != nil && readErr != io.EOF && readErr != bufio.ErrBufferFull {
+			return readErr
+		}
+
+		if len(chunk) > 0 {
+			stats.Lines++
+
+			line := string(chunk)
+			text := strings.TrimRight(line, "\r\n")
+
+			if readErr == bufio.ErrBufferFull || len(text) > MaxLineLength {
+				stats.Unparsed++
+				if err := copyLine(in, out, chunk, readErr); err != nil {
+					return err
+				}
+				readErr = nil
+			} else {
+				if err := fn(text, out); err != nil {
+					out.Flush()
+					return err
+				}
+
+				if _, err := out.WriteString(line[len(text):]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return out.Flush()
+		}
+	}
+}
+
+// copyLine writes the chunk read with the error, and the rest of its line, unchanged.
+func copyLine(in *bufio.Reader, out *bufio.Writer, chunk []byte, readErr error) error {
+	for {
+		if _, err := out.Write(chunk); err != nil {
+			return err
+		}
+
+		if readErr != bufio.ErrBufferFull {
+			return nil
+		}
+
+		chunk, readErr = in.ReadSlice('\n')
+		if readErr != nil && readErr != io.EOF && readErr != bufio.ErrBufferFull {
+			return readErr
+		}
+	}
+}
+
+// appendMembers returns the JSON object line with the members added before its closing brace, and reports
+// whether the line was an object.
+func appendMembers(line string, obj object) (string, bool) {
+	trimmed := strings.TrimRight(line, " \t")
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return line, false
+	}
+
+	if len(obj) == 0 {
+		return line, true
+	}
+
+	b, err := obj.MarshalJSON()
+	if err != nil {
+		return line, false
+	}
+
+	members := string(b[1 : len(b)-1])
+	if body := strings.TrimSpace(trimmed[1 : len(trimmed)-1]); body != "" {
+		members = "," + members
+	}
+
+	return trimmed[:len(trimmed)-1] + members + "}", true
+}
@@ -0,0 +1,161 @@
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// testAccessLog is the access log enriched in tests.
+var testAccessLog = strings.Join([]string{
+	`203.0.113.10 - frank [10/Oct/2022:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326`,
+	`2001:db8::1 - - [10/Oct/2022:13:55:37 -0700] "GET /b HTTP/1.1" 404 - "https://example.com/" "curl \"8\""`,
+	`garbage`,
+	`198.51.100.1 - - [10/Oct/2022:13:55:38 -0700] "POST / HTTP/1.1" 201 5 "-" "Go" 0.003`,
+	`203.0.113.11 - - [10/Oct/2022:13:55:39 -0700] "GET /?q=\x22a\x5C HTTP/1.1" 200 7 "-" "Mozilla \x22x\x22"`,
+}, "\r\n")
+
+// TestAccessLogEnrich tests the Enrich method.
+func TestAccessLogEnrich(t *testing.T) {
+	tests := []struct {
+		name   string
+		params AccessLogParams
+		want   []string
+	}{
+		{
+			name: "log",
+			want: []string{
+				`203.0.113.10 - frank [10/Oct/2022:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326` +
+					` "64500" "Example B.V." "NL" "EXAMPLE-NET"`,
+				`2001:db8::1 - - [10/Oct/2022:13:55:37 -0700] "GET /b HTTP/1.1" 404 - "https://example.com/"` +
+					` "curl \"8\"" "-" "-" "US" "DOC-NET"`,
+				`garbage`,
+				`198.51.100.1 - - [10/Oct/2022:13:55:38 -0700] "POST / HTTP/1.1" 201 5 "-" "Go" 0.003` +
+					` "-" "-" "-" "-"`,
+				`203.0.113.11 - - [10/Oct/2022:13:55:39 -0700] "GET /?q=\x22a\x5C HTTP/1.1" 200 7 "-"` +
+					` "Mozilla \x22x\x22" "64500" "Example B.V." "NL" "EXAMPLE-NET"`,
+			},
+		},
+		{
+			name:   "json",
+			params: AccessLogParams{Format: FormatJSON, Fields: []Field{FieldASN, FieldNetblock}},
+			want: []string{
+				`{"remote_addr":"203.0.113.10","remote_user":"frank","time_local":"10/Oct/2022:13:55:36 -0700",` +
+					`"request":"GET /a.gif HTTP/1.0","status":200,"body_bytes_sent":2326,"asn":64500,` +
+					`"netblock":"203.0.113.0 - 203.0.113.127"}`,
+				`{"remote_addr":"2001:db8::1","time_local":"10/Oct/2022:13:55:37 -0700","request":"GET /b HTTP/1.1",` +
+					`"status":404,"body_bytes_sent":0,"http_referer":"https://example.com/",` +
+					`"http_user_agent":"curl \"8\"","netblock":"2001:db8:: - 2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"}`,
+				`{"line":"garbage"}`,
+				`{"remote_addr":"198.51.100.1","time_local":"10/Oct/2022:13:55:38 -0700","request":"POST / HTTP/1.1",` +
+					`"status":201,"body_bytes_sent":5,"http_user_agent":"Go","extra":"0.003"}`,
+				`{"remote_addr":"203.0.113.11","time_local":"10/Oct/2022:13:55:39 -0700",` +
+					`"request":"GET /?q=\"a\\ HTTP/1.1","status":200,"body_bytes_sent":7,` +
+					`"http_user_agent":"Mozilla \"x\"","asn":64500,"netblock":"203.0.113.0 - 203.0.113.127"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAccessLog(NewResolver(newFakeService(), ResolverParams{}), tt.params)
+
+			var b bytes.Buffer
+			stats, err := a.Enrich(context.Background(), strings.NewReader(testAccessLog), &b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := b.String(), strings.Join(tt.want, "\r\n"); got != want {
+				t.Errorf("Enrich() = %s, want %s", got, want)
+			}
+
+			if *stats != (Stats{Lines: 5, Enriched: 3, Unparsed: 1}) {
+				t.Errorf("Enrich() stats = %+v", *stats)
+			}
+		})
+	}
+}
+
+// TestUnquote tests the unquote function.
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`GET / HTTP/1.1`, `GET / HTTP/1.1`},
+		{`curl \"8\" \\`, `curl "8" \`},
+		{`curl \x228\x22 \x5C`, `curl "8" \`},
+		{`a\tb\x0Ac`, "a\tb\nc"},
+		{`\xC3\xA9t\xc3\xa9`, `été`},
+		{`\xZZ \x2 \q \`, `\xZZ \x2 \q \`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := unquote(tt.value); got != tt.want {
+				t.Errorf("unquote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAccessLogEnrichError tests the Enrich method with failing lookups and invalid parameters.
+func TestAccessLogEnrichError(t *testing.T) {
+	resolver := NewResolver(newFakeService(), ResolverParams{})
+	log := "203.0.113.1 - - [10/Oct/2022:13:55:36 -0700] \"GET / HTTP/1.0\" 200 1\n" +
+		"192.0.2.66 - - [10/Oct/2022:13:55:36 -0700] \"GET / HTTP/1.0\" 200 1\n"
+
+	var b bytes.Buffer
+	_, err := NewAccessLog(resolver, AccessLogParams{}).Enrich(context.Background(), strings.NewReader(log), &b)
+	if err == nil || err.Error() != "line 2: request failed" {
+		t.Errorf("Enrich() error = %v, want line 2: request failed", err)
+	}
+
+	if !strings.HasPrefix(b.String(), "203.0.113.1 ") || strings.Count(b.String(), "\n") != 1 {
+		t.Errorf("Enrich() = %q, want the first line", b.String())
+	}
+
+	a := NewAccessLog(resolver, AccessLogParams{Format: "xml"})
+	if _, err = a.Enrich(context.Background(), strings.NewReader(log), &b); err == nil {
+		t.Errorf("Enrich() expected error")
+	}
+}
+
+// TestAccessLogEnrichLongLines tests the Enrich method with lines longer than MaxLineLength.
+func TestAccessLogEnrichLongLines(t *testing.T) {
+	line := `203.0.113.10 - - [10/Oct/2022:13:55:36 -0700] "GET / HTTP/1.0" 200 1`
+	long := line + " " + strings.Repeat("x", MaxLineLength-len(line))
+	huge := line + " " + strings.Repeat("x", 3*MaxLineLength)
+
+	log := line + "\n" + long + "\r\n" + huge + "\n" + line
+	want := line + ` "64500"` + "\n" + long + "\r\n" + huge + "\n" + line + ` "64500"`
+
+	a := NewAccessLog(NewResolver(newFakeService(), ResolverParams{}), AccessLogParams{Fields: []Field{FieldASN}})
+
+	var b bytes.Buffer
+	stats, err := a.Enrich(context.Background(), strings.NewReader(log), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != want {
+		t.Errorf("Enrich() wrote %d bytes, want %d", b.Len(), len(want))
+	}
+
+	if *stats != (Stats{Lines: 4, Enriched: 2, Unparsed: 2}) {
+		t.Errorf("Enrich() stats = %+v", *stats)
+	}
+}
+
+// TestParseField tests the ParseField function.
+func TestParseField(t *testing.T) {
+	if field, err := ParseField("as_name"); field != FieldASName || err != nil {
+		t.Errorf("ParseField(as_name) = %v, %v", field, err)
+	}
+
+	_, err := ParseField("city")
+	if err == nil || err.Error() != `invalid argument: "city" is unknown field` {
+		t.Errorf("ParseField(city) error = %v", err)
+	}
+}
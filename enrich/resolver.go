@@ -0,0 +1,317 @@
+// Package enrich adds netblock fields, such as the ASN, organization and country, to log records.
+package enrich
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"net/netip"
+	"strconv"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/cidrset"
+)
+
+// Field is a netblock field added to log records.
+type Field string
+
+const (
+	// FieldASN is the AS number.
+	FieldASN Field = "asn"
+
+	// FieldASName is the AS name.
+	FieldASName Field = "as_name"
+
+	// FieldOrg is the organization name.
+	FieldOrg Field = "org"
+
+	// FieldCountry is the ISO 3166-1 alpha-2 country code.
+	FieldCountry Field = "country"
+
+	// FieldNetname is the netblock name.
+	FieldNetname Field = "netname"
+
+	// FieldNetblock is the range of the netblock.
+	FieldNetblock Field = "netblock"
+)
+
+// DefaultFields is the list of fields added by default.
+var DefaultFields = []Field{FieldASN, FieldOrg, FieldCountry, FieldNetname}
+
+// allFields is the list of known fields.
+var allFields = []Field{FieldASN, FieldASName, FieldOrg, FieldCountry, FieldNetname, FieldNetblock}
+
+// ParseField returns the field with the name.
+func ParseField(name string) (Field, error) {
+	for _, field := range allFields {
+		if string(field) == name {
+			return field, nil
+		}
+	}
+
+	return "", &ipnetblocks.ArgError{Name: name, Message: "is unknown field"}
+}
+
+// Netblock is the summary of the netblocks containing an IP address.
+type Netblock struct {
+	// Range is the range of the most specific netblock.
+	Range ipnetblocks.Range
+
+	// ASN is the AS number of the most specific netblock which has one.
+	ASN int
+
+	// ASName is the AS name of the most specific netblock which has one.
+	ASName string
+
+	// Org is the organization name of the most specific netblock which has one.
+	Org string
+
+	// Country is the country code of the most specific netblock which has one.
+	Country string
+
+	// Netname is the name of the most specific netblock which has one.
+	Netname string
+}
+
+// Value returns the value of the field, or an empty string if the netblock is nil or has no value.
+func (n *Netblock) Value(field Field) string {
+	if n == nil {
+		return ""
+	}
+
+	switch field {
+	case FieldASN:
+		if n.ASN > 0 {
+			return strconv.Itoa(n.ASN)
+		}
+	case FieldASName:
+		return n.ASName
+	case FieldOrg:
+		return n.Org
+	case FieldCountry:
+		return n.Country
+	case FieldNetname:
+		return n.Netname
+	case FieldNetblock:
+		if n.Range.IsValid() {
+			return n.Range.String()
+		}
+	}
+
+	return ""
+}
+
+// newNetblock returns the summary of the netblocks returned by GetByIP, or nil if there are none.
+func newNetblock(inetnums []ipnetblocks.Inetnum) *Netblock {
+	h := ipnetblocks.NewHierarchy(inetnums, ipnetblocks.PreferNarrowest)
+
+	chain := h.Chain()
+	if len(chain) == 0 {
+		return nil
+	}
+
+	n := &Netblock{}
+	n.Range, _ = chain[len(chain)-1].Range()
+
+	// Walk from the widest netblock down, so that more specific values win.
+	for _, inetnum := range chain {
+		if inetnum.AS.ASN > 0 {
+			n.ASN, n.ASName = inetnum.AS.ASN, inetnum.AS.Name
+		}
+
+		setValue(&n.Org, inetnum.Org.Name)
+		setValue(&n.Country, inetnum.Country)
+		setValue(&n.Netname, inetnum.Netname)
+	}
+
+	return n
+}
+
+// setValue sets the value unless it's empty.
+func setValue(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// ResolverParams is used to create Resolver. None of parameters are mandatory.
+type ResolverParams struct {
+	// CacheSize is the maximum number of cached netblocks. Default: 10000.
+	CacheSize int
+
+	// Options are passed to GetByIP and GetByCIDR.
+	Options []ipnetblocks.Option
+}
+
+// Resolver looks up IP addresses with a bounded cache. A looked up netblock is cached by the part of its range
+// which has no narrower netblocks, so the addresses it contains are answered without requests and the results
+// don't depend on the lookup order. Narrower netblocks are found with a GetByCIDR request for the netblock;
+// when they can't all be listed in one page, only the address is cached. Addresses without netblocks are cached
+// individually. Resolver isn't safe for concurrent use.
+type Resolver struct {
+	service ipnetblocks.IPNetblocks
+	opts    []ipnetblocks.Option
+	size    int
+
+	lru      *list.List
+	prefixes map[netip.Prefix]*list.Element
+	bits     map[int]int // number of cached prefixes by length
+
+	// Requests is the number of GetByIP and GetByCIDR calls made.
+	Requests int
+
+	// Hits is the number of lookups answered from the cache.
+	Hits int
+}
+
+// cacheEntry is a cached netblock with the prefixes covering its range.
+type cacheEntry struct {
+	netblock *Netblock
+	prefixes []netip.Prefix
+}
+
+// NewResolver creates Resolver.
+func NewResolver(service ipnetblocks.IPNetblocks, params ResolverParams) *Resolver {
+	size := params.CacheSize
+	if size <= 0 {
+		size = 10000
+	}
+
+	return &Resolver{
+		service:  service,
+		opts:     params.Options,
+		size:     size,
+		lru:      list.New(),
+		prefixes: make(map[netip.Prefix]*list.Element),
+		bits:     make(map[int]int),
+	}
+}
+
+// Lookup returns the netblock of the IP address, or nil if there is none.
+func (r *Resolver) Lookup(ctx context.Context, ip netip.Addr) (*Netblock, error) {
+	ip = ip.WithZone("").Unmap()
+	if !ip.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "ip", Message: "can not be empty"}
+	}
+
+	if elem := r.cached(ip); elem != nil {
+		r.Hits++
+		r.lru.MoveToFront(elem)
+
+		return elem.Value.(*cacheEntry).netblock, nil
+	}
+
+	r.Requests++
+	resp, _, err := r.service.GetByIP(ctx, net.IP(ip.AsSlice()), r.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	n := newNetblock(resp.Result.Inetnums)
+
+	prefixes, err := r.cachedPrefixes(ctx, ip, n)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(&cacheEntry{netblock: n, prefixes: prefixes})
+
+	return n, nil
+}
+
+// cachedPrefixes returns the prefixes to cache the netblock of the IP address by: the prefix of the netblock
+// containing the address without narrower netblocks inside it, or only the address if they are not all known.
+func (r *Resolver) cachedPrefixes(ctx context.Context, ip netip.Addr, n *Netblock) ([]netip.Prefix, error) {
+	single := []netip.Prefix{netip.PrefixFrom(ip, ip.BitLen())}
+	if n == nil || !n.Range.Contains(ip) {
+		return single, nil
+	}
+
+	var prefix netip.Prefix
+	for _, p := range n.Range.Prefixes() {
+		if p.Contains(ip) {
+			prefix = p
+			break
+		}
+	}
+
+	if prefix.IsSingleIP() {
+		return single, nil
+	}
+
+	r.Requests++
+	resp, _, err := r.service.GetByCIDR(ctx, net.IPNet{
+		IP:   net.IP(prefix.Addr().AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}, r.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Result.Next != nil {
+		return single, nil
+	}
+
+	set := cidrset.New(prefix)
+	for _, inetnum := range resp.Result.Inetnums {
+		nr, err := inetnum.Range()
+		if err != nil {
+			return single, nil
+		}
+
+		if nr != n.Range && n.Range.Contains(nr.First) && n.Range.Contains(nr.Last) {
+			set = set.Difference(cidrset.FromRanges(nr))
+		}
+	}
+
+	if !set.Contains(ip) {
+		return single, nil
+	}
+
+	return set.Prefixes(), nil
+}
+
+// cached returns the cache element of the narrowest cached prefix containing the IP address.
+func (r *Resolver) cached(ip netip.Addr) *list.Element {
+	for bits := ip.BitLen(); bits >= 0; bits-- {
+		if r.bits[bits] == 0 {
+			continue
+		}
+
+		prefix, _ := ip.Prefix(bits)
+		if elem, ok := r.prefixes[prefix]; ok {
+			return elem
+		}
+	}
+
+	return nil
+}
+
+// store adds the entry to the cache, evicting the least recently used ones when the cache is full.
+func (r *Resolver) store(entry *cacheEntry) {
+	for r.lru.Len() >= r.size {
+		r.evict(r.lru.Back())
+	}
+
+	elem := r.lru.PushFront(entry)
+	for _, prefix := range entry.prefixes {
+		if old, ok := r.prefixes[prefix]; ok {
+			r.evict(old)
+		}
+
+		r.prefixes[prefix] = elem
+		r.bits[prefix.Bits()]++
+	}
+}
+
+// evict removes the cache element.
+func (r *Resolver) evict(elem *list.Element) {
+	entry := r.lru.Remove(elem).(*cacheEntry)
+
+	for _, prefix := range entry.prefixes {
+		if r.prefixes[prefix] == elem {
+			delete(r.prefixes, prefix)
+			r.bits[prefix.Bits()]--
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// fakeService answers GetByIP with the configured netblocks containing the address.
+type fakeService struct {
+	ipnetblocks.IPNetblocks
+
+	inetnums []ipnetblocks.Inetnum
+	calls    []string
+}
+
+// GetByIP returns the netblocks containing the IP address. The address 192.0.2.66 fails.
+func (s *fakeService) GetByIP(_ context.Context, ip net.IP, _ ...ipnetblocks.Option) (
+	*ipnetblocks.IPNetblocksResponse, *ipnetblocks.Response, error) {
+	s.calls = append(s.calls, ip.String())
+
+	addr, _ := netip.AddrFromSlice(ip)
+	addr = addr.Unmap()
+	if addr == netip.MustParseAddr("192.0.2.66") {
+		return nil, nil, errors.New("request failed")
+	}
+
+	resp := &ipnetblocks.IPNetblocksResponse{Search: addr.String()}
+	for _, inetnum := range s.inetnums {
+		if r, err := inetnum.Range(); err == nil && r.Contains(addr) {
+			resp.Result.Inetnums = append(resp.Result.Inetnums, inetnum)
+		}
+	}
+
+	return resp, nil, nil
+}
+
+// GetByCIDR returns the netblocks overlapping the CIDR, i.e. containing it or contained in it.
+func (s *fakeService) GetByCIDR(_ context.Context, ip net.IPNet, _ ...ipnetblocks.Option) (
+	*ipnetblocks.IPNetblocksResponse, *ipnetblocks.Response, error) {
+	s.calls = append(s.calls, ip.String())
+
+	addr, _ := netip.AddrFromSlice(ip.IP)
+	ones, _ := ip.Mask.Size()
+	cidr := ipnetblocks.PrefixRange(netip.PrefixFrom(addr.Unmap(), ones))
+
+	resp := &ipnetblocks.IPNetblocksResponse{Search: ip.String()}
+	for _, inetnum := range s.inetnums {
+		if r, err := inetnum.Range(); err == nil && !r.Last.Less(cidr.First) && !cidr.Last.Less(r.First) {
+			resp.Result.Inetnums = append(resp.Result.Inetnums, inetnum)
+		}
+	}
+
+	return resp, nil, nil
+}
+
+// newFakeService returns the service with test netblocks.
+func newFakeService() *fakeService {
+	return &fakeService{inetnums: []ipnetblocks.Inetnum{
+		{
+			Inetnum: "203.0.113.0 - 203.0.113.255",
+			Netname: "EXAMPLE-NET",
+			Country: "NL",
+			Org:     ipnetblocks.Organization{Name: "Example B.V."},
+		},
+		{
+			Inetnum: "203.0.113.0 - 203.0.113.127",
+			Parent:  "203.0.113.0 - 203.0.113.255",
+			AS:      ipnetblocks.AS{ASN: 64500, Name: "EXAMPLE-AS"},
+		},
+		{
+			Inetnum: "2001:db8:: - 2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+			Netname: "DOC-NET",
+			Country: "US",
+		},
+	}}
+}
+
+// TestResolverLookup tests the Lookup method.
+func TestResolverLookup(t *testing.T) {
+	service := newFakeService()
+	resolver := NewResolver(service, ResolverParams{})
+	ctx := context.Background()
+
+	netblock, err := resolver.Lookup(ctx, netip.MustParseAddr("203.0.113.10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Netblock{
+		Range:   ipnetblocks.Range{First: netip.MustParseAddr("203.0.113.0"), Last: netip.MustParseAddr("203.0.113.127")},
+		ASN:     64500,
+		ASName:  "EXAMPLE-AS",
+		Org:     "Example B.V.",
+		Country: "NL",
+		Netname: "EXAMPLE-NET",
+	}
+	if netblock == nil || *netblock != want {
+		t.Errorf("Lookup() = %+v, want %+v", netblock, want)
+	}
+
+	for _, ip := range []string{"203.0.113.127", "::ffff:203.0.113.1", "203.0.113.200", "203.0.113.201",
+		"198.51.100.1", "198.51.100.1", "198.51.100.2", "2001:db8::1", "2001:db8:1::1"} {
+		if _, err = resolver.Lookup(ctx, netip.MustParseAddr(ip)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantCalls := []string{"203.0.113.10", "203.0.113.0/25", "203.0.113.200", "203.0.113.0/24", "198.51.100.1",
+		"198.51.100.2", "2001:db8::1", "2001:db8::/32"}
+	if len(service.calls) != len(wantCalls) {
+		t.Fatalf("API calls = %v, want %v", service.calls, wantCalls)
+	}
+
+	for i := range wantCalls {
+		if service.calls[i] != wantCalls[i] {
+			t.Errorf("API calls = %v, want %v", service.calls, wantCalls)
+		}
+	}
+
+	if resolver.Requests != 8 || resolver.Hits != 5 {
+		t.Errorf("Requests, Hits = %d, %d, want 8, 5", resolver.Requests, resolver.Hits)
+	}
+
+	netblock, err = resolver.Lookup(ctx, netip.MustParseAddr("198.51.100.1"))
+	if err != nil || netblock != nil {
+		t.Errorf("Lookup(198.51.100.1) = %v, %v, want nil", netblock, err)
+	}
+
+	if _, err = resolver.Lookup(ctx, netip.MustParseAddr("192.0.2.66")); err == nil {
+		t.Errorf("Lookup(192.0.2.66) expected error")
+	}
+
+	if _, err = resolver.Lookup(ctx, netip.Addr{}); err == nil {
+		t.Errorf("Lookup() expected error")
+	}
+}
+
+// TestResolverEviction tests that the cache is bounded.
+func TestResolverEviction(t *testing.T) {
+	service := newFakeService()
+	resolver := NewResolver(service, ResolverParams{CacheSize: 2})
+	ctx := context.Background()
+
+	for _, ip := range []string{"203.0.113.1", "198.51.100.1", "203.0.113.2", "2001:db8::1", "203.0.113.3",
+		"198.51.100.1"} {
+		if _, err := resolver.Lookup(ctx, netip.MustParseAddr(ip)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The /25 stays cached while used, the unknown address and then the IPv6 netblock are evicted.
+	if resolver.Requests != 6 || resolver.Hits != 2 {
+		t.Errorf("Requests, Hits = %d, %d, want 6, 2", resolver.Requests, resolver.Hits)
+	}
+
+	if resolver.lru.Len() != 2 || len(resolver.prefixes) > 2 {
+		t.Errorf("cache holds %d entries and %d prefixes, want 2", resolver.lru.Len(), len(resolver.prefixes))
+	}
+}
+
+// TestResolverLookupOrder tests that results of the Lookup method don't depend on the order of lookups.
+func TestResolverLookupOrder(t *testing.T) {
+	ctx := context.Background()
+	ips := []string{"203.0.113.10", "203.0.113.127", "203.0.113.128", "203.0.113.200", "2001:db8::1", "198.51.100.1"}
+
+	want := make(map[string]*Netblock, len(ips))
+	for _, ip := range ips {
+		netblock, err := NewResolver(newFakeService(), ResolverParams{}).Lookup(ctx, netip.MustParseAddr(ip))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want[ip] = netblock
+	}
+
+	forward := NewResolver(newFakeService(), ResolverParams{})
+	reverse := NewResolver(newFakeService(), ResolverParams{})
+
+	for i := range ips {
+		for _, tt := range []struct {
+			resolver *Resolver
+			ip       string
+		}{{forward, ips[i]}, {reverse, ips[len(ips)-1-i]}} {
+			got, err := tt.resolver.Lookup(ctx, netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want[tt.ip]) {
+				t.Errorf("Lookup(%s) = %+v, want %+v", tt.ip, got, want[tt.ip])
+			}
+		}
+	}
+
+	if reverse.Hits == 0 || forward.Hits == 0 {
+		t.Errorf("Hits = %d, %d, want cached lookups", forward.Hits, reverse.Hits)
+	}
+}
@@ -0,0 +1,107 @@
+package enrich
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// SuricataParams is used to create Suricata. None of parameters are mandatory.
+type SuricataParams struct {
+	// Fields is the list of added fields. Default: DefaultFields.
+	Fields []Field
+
+	// SrcNamespace is the key of the object with fields of the source address. Default: "src_netblock".
+	SrcNamespace string
+
+	// DestNamespace is the key of the object with fields of the destination address. Default: "dest_netblock".
+	DestNamespace string
+}
+
+// Suricata enriches Suricata EVE JSON logs with netblock fields of "src_ip" and "dest_ip" addresses. Fields are
+// added as nested objects with snake_case keys, like the other EVE sections.
+type Suricata struct {
+	resolver *Resolver
+	params   SuricataParams
+}
+
+// NewSuricata creates Suricata.
+func NewSuricata(resolver *Resolver, params SuricataParams) *Suricata {
+	if params.Fields == nil {
+		params.Fields = DefaultFields
+	}
+
+	params.SrcNamespace = defaults.String(params.SrcNamespace, "src_netblock")
+	params.DestNamespace = defaults.String(params.DestNamespace, "dest_netblock")
+
+	return &Suricata{resolver: resolver, params: params}
+}
+
+// eveEvent is the part of an EVE event with addresses.
+type eveEvent struct {
+	SrcIP  string `json:"src_ip"`
+	DestIP string `json:"dest_ip"`
+}
+
+// Enrich reads EVE events line by line and writes them with netblock objects appended. Lines which aren't
+// JSON objects are written unchanged. It stops at the first failed lookup.
+func (s *Suricata) Enrich(ctx context.Context, r io.Reader, w io.Writer) (*Stats, error) {
+	stats := &Stats{}
+	err := eachLine(r, w, stats, func(line string, out *bufio.Writer) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var event eveEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			stats.Unparsed++
+			_, err = out.WriteString(line)
+			return err
+		}
+
+		var obj object
+		for _, side := range []struct{ ip, namespace string }{
+			{event.SrcIP, s.params.SrcNamespace},
+			{event.DestIP, s.params.DestNamespace},
+		} {
+			netblock, err := lookupString(ctx, s.resolver, side.ip)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", stats.Lines, err)
+			}
+
+			if fields := appendFields(nil, "", s.params.Fields, netblock); len(fields) > 0 {
+				obj = append(obj, member{side.namespace, fields})
+			}
+		}
+
+		if len(obj) > 0 {
+			stats.Enriched++
+		}
+
+		enriched, ok := appendMembers(line, obj)
+		if !ok {
+			stats.Unparsed++
+		}
+
+		_, err := out.WriteString(enriched)
+
+		return err
+	})
+
+	return stats, err
+}
+
+// lookupString returns the netblock of the address, or nil if the string isn't an address.
+func lookupString(ctx context.Context, resolver *Resolver, s string) (*Netblock, error) {
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return nil, nil
+	}
+
+	return resolver.Lookup(ctx, ip)
+}
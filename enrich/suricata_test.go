@@ -0,0 +1,53 @@
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestSuricataEnrich tests the Enrich method.
+func TestSuricataEnrich(t *testing.T) {
+	log := strings.Join([]string{
+		`{"timestamp":"2022-10-10T12:00:00.000000+0000","event_type":"alert","src_ip":"203.0.113.10",` +
+			`"src_port":51000,"dest_ip":"2001:db8::1","dest_port":443,"proto":"TCP"}`,
+		`{"event_type":"stats","stats":{"uptime":10}}`,
+		`{"event_type":"flow","src_ip":"198.51.100.1","dest_ip":"203.0.113.200"} `,
+		`not json`,
+	}, "\n")
+
+	want := strings.Join([]string{
+		`{"timestamp":"2022-10-10T12:00:00.000000+0000","event_type":"alert","src_ip":"203.0.113.10",` +
+			`"src_port":51000,"dest_ip":"2001:db8::1","dest_port":443,"proto":"TCP",` +
+			`"source":{"asn":64500,"netname":"EXAMPLE-NET"},"dest_netblock":{"netname":"DOC-NET"}}`,
+		`{"event_type":"stats","stats":{"uptime":10}}`,
+		`{"event_type":"flow","src_ip":"198.51.100.1","dest_ip":"203.0.113.200",` +
+			`"dest_netblock":{"netname":"EXAMPLE-NET"}}`,
+		`not json`,
+	}, "\n")
+
+	s := NewSuricata(NewResolver(newFakeService(), ResolverParams{}), SuricataParams{
+		Fields:       []Field{FieldASN, FieldNetname},
+		SrcNamespace: "source",
+	})
+
+	var b bytes.Buffer
+	stats, err := s.Enrich(context.Background(), strings.NewReader(log), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != want {
+		t.Errorf("Enrich() = %s, want %s", b.String(), want)
+	}
+
+	if *stats != (Stats{Lines: 4, Enriched: 2, Unparsed: 1}) {
+		t.Errorf("Enrich() stats = %+v", *stats)
+	}
+
+	_, err = s.Enrich(context.Background(), strings.NewReader(`{"src_ip":"192.0.2.66"}`), &b)
+	if err == nil || err.Error() != "line 1: request failed" {
+		t.Errorf("Enrich() error = %v, want line 1: request failed", err)
+	}
+}
@@ -0,0 +1,291 @@
+package enrich
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// ZeekParams is used to create Zeek. None of parameters are mandatory.
+type ZeekParams struct {
+	// Fields is the list of added fields. Default: DefaultFields.
+	Fields []Field
+
+	// OrigNamespace is the prefix of fields of the originator address. Default: "orig_netblock".
+	OrigNamespace string
+
+	// RespNamespace is the prefix of fields of the responder address. Default: "resp_netblock".
+	RespNamespace string
+}
+
+// Zeek enriches Zeek logs in the TSV or JSON format with netblock fields of "id.orig_h" and "id.resp_h"
+// addresses. Fields are named like members of Zeek records, e.g. "orig_netblock.asn", and the ASN is typed
+// as count in TSV headers.
+type Zeek struct {
+	resolver *Resolver
+	params   ZeekParams
+}
+
+// NewZeek creates Zeek.
+func NewZeek(resolver *Resolver, params ZeekParams) *Zeek {
+	if params.Fields == nil {
+		params.Fields = DefaultFields
+	}
+
+	params.OrigNamespace = defaults.String(params.OrigNamespace, "orig_netblock")
+	params.RespNamespace = defaults.String(params.RespNamespace, "resp_netblock")
+
+	return &Zeek{resolver: resolver, params: params}
+}
+
+// zeekTSV is the state of a TSV log taken from its header.
+type zeekTSV struct {
+	separator string
+	unset     string
+	empty     string
+	orig      int
+	resp      int
+}
+
+// zeekConn is the part of a JSON log entry with addresses.
+type zeekConn struct {
+	OrigH string `json:"id.orig_h"`
+	RespH string `json:"id.resp_h"`
+}
+
+// Enrich reads the log line by line and writes it with fields added. TSV headers get the added columns,
+// which are unset for missing values. Logs without address columns and unexpected lines are written unchanged.
+// It stops at the first failed lookup.
+func (z *Zeek) Enrich(ctx context.Context, r io.Reader, w io.Writer) (*Stats, error) {
+	tsv := &zeekTSV{separator: "\t", unset: "-", empty: "(empty)", orig: -1, resp: -1}
+
+	stats := &Stats{}
+	err := eachLine(r, w, stats, func(line string, out *bufio.Writer) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var enriched string
+		var err error
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			enriched = z.header(tsv, line)
+		case strings.HasPrefix(line, "{"):
+			enriched, err = z.jsonLine(ctx, line, stats)
+		default:
+			enriched, err = z.tsvLine(ctx, tsv, line, stats)
+		}
+
+		if err != nil {
+			return fmt.Errorf("line %d: %w", stats.Lines, err)
+		}
+
+		_, err = out.WriteString(enriched)
+
+		return err
+	})
+
+	return stats, err
+}
+
+// header updates the TSV state with the header line and returns it with added columns.
+func (z *Zeek) header(tsv *zeekTSV, line string) string {
+	if value := strings.TrimPrefix(line, "#separator "); value != line {
+		tsv.separator = unescapeZeek(value)
+		return line
+	}
+
+	parts := strings.Split(line, tsv.separator)
+	switch parts[0] {
+	case "#unset_field":
+		if len(parts) > 1 {
+			tsv.unset = parts[1]
+		}
+	case "#empty_field":
+		if len(parts) > 1 {
+			tsv.empty = parts[1]
+		}
+	case "#fields":
+		tsv.orig, tsv.resp = -1, -1
+		for i, name := range parts[1:] {
+			switch name {
+			case "id.orig_h":
+				tsv.orig = i
+			case "id.resp_h":
+				tsv.resp = i
+			}
+		}
+
+		var names []string
+		for _, namespace := range z.namespaces(tsv) {
+			for _, field := range z.params.Fields {
+				names = append(names, namespace+"."+string(field))
+			}
+		}
+
+		return strings.Join(append(parts, names...), tsv.separator)
+	case "#types":
+		if tsv.orig < 0 && tsv.resp < 0 {
+			return line
+		}
+
+		var types []string
+		for range z.namespaces(tsv) {
+			for _, field := range z.params.Fields {
+				if field == FieldASN {
+					types = append(types, "count")
+				} else {
+					types = append(types, "string")
+				}
+			}
+		}
+
+		return strings.Join(append(parts, types...), tsv.separator)
+	}
+
+	return line
+}
+
+// namespaces returns the namespaces of the address columns of the TSV log.
+func (z *Zeek) namespaces(tsv *zeekTSV) []string {
+	var namespaces []string
+	if tsv.orig >= 0 {
+		namespaces = append(namespaces, z.params.OrigNamespace)
+	}
+	if tsv.resp >= 0 {
+		namespaces = append(namespaces, z.params.RespNamespace)
+	}
+
+	return namespaces
+}
+
+// tsvLine returns the TSV data line with added columns.
+func (z *Zeek) tsvLine(ctx context.Context, tsv *zeekTSV, line string, stats *Stats) (string, error) {
+	if tsv.orig < 0 && tsv.resp < 0 {
+		return line, nil
+	}
+
+	values := strings.Split(line, tsv.separator)
+
+	var columns []string
+	found := false
+
+	for _, i := range []int{tsv.orig, tsv.resp} {
+		if i < 0 {
+			continue
+		}
+
+		if i >= len(values) {
+			stats.Unparsed++
+			return line, nil
+		}
+
+		netblock, err := lookupString(ctx, z.resolver, values[i])
+		if err != nil {
+			return "", err
+		}
+
+		found = found || netblock != nil
+
+		for _, field := range z.params.Fields {
+			columns = append(columns, tsv.value(netblock, field))
+		}
+	}
+
+	if found {
+		stats.Enriched++
+	}
+
+	return strings.Join(append(values, columns...), tsv.separator), nil
+}
+
+// value returns the escaped TSV value of the field.
+func (tsv *zeekTSV) value(netblock *Netblock, field Field) string {
+	if netblock == nil {
+		return tsv.unset
+	}
+
+	value := netblock.Value(field)
+	if value == "" {
+		if field == FieldASN {
+			return tsv.unset
+		}
+		return tsv.empty
+	}
+
+	return escapeZeek(value, tsv.separator)
+}
+
+// jsonLine returns the JSON log entry with added fields.
+func (z *Zeek) jsonLine(ctx context.Context, line string, stats *Stats) (string, error) {
+	var conn zeekConn
+	if err := json.Unmarshal([]byte(line), &conn); err != nil {
+		stats.Unparsed++
+		return line, nil
+	}
+
+	var obj object
+	for _, side := range []struct{ ip, namespace string }{
+		{conn.OrigH, z.params.OrigNamespace},
+		{conn.RespH, z.params.RespNamespace},
+	} {
+		netblock, err := lookupString(ctx, z.resolver, side.ip)
+		if err != nil {
+			return "", err
+		}
+
+		obj = appendFields(obj, side.namespace+".", z.params.Fields, netblock)
+	}
+
+	if len(obj) > 0 {
+		stats.Enriched++
+	}
+
+	enriched, _ := appendMembers(line, obj)
+
+	return enriched, nil
+}
+
+// unescapeZeek replaces \xHH escapes of the header value.
+func unescapeZeek(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if c, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// escapeZeek escapes backslashes, the separator and non-printable characters of the value as \xHH, like
+// the Zeek ASCII writer.
+func escapeZeek(s, separator string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' || c < 0x20 || c == 0x7f || strings.HasPrefix(s[i:], separator) {
+			fmt.Fprintf(&b, "\\x%02x", c)
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestZeekEnrich tests the Enrich method.
+func TestZeekEnrich(t *testing.T) {
+	tests := []struct {
+		name   string
+		params ZeekParams
+		log    []string
+		want   []string
+		stats  Stats
+	}{
+		{
+			name:   "tsv",
+			params: ZeekParams{Fields: []Field{FieldASN, FieldOrg}},
+			log: []string{
+				`#separator \x09`,
+				"#set_separator\t,",
+				"#empty_field\t(empty)",
+				"#unset_field\t-",
+				"#path\tconn",
+				"#fields\tts\tid.orig_h\tid.orig_p\tid.resp_h\tid.resp_p",
+				"#types\ttime\taddr\tport\taddr\tport",
+				"1665400000.000000\t203.0.113.10\t51000\t2001:db8::1\t443",
+				"1665400001.000000\t198.51.100.1\t51001\t203.0.113.200\t80",
+				"#close\t2022-10-10-12-00-00",
+			},
+			want: []string{
+				`#separator \x09`,
+				"#set_separator\t,",
+				"#empty_field\t(empty)",
+				"#unset_field\t-",
+				"#path\tconn",
+				"#fields\tts\tid.orig_h\tid.orig_p\tid.resp_h\tid.resp_p" +
+					"\torig_netblock.asn\torig_netblock.org\tresp_netblock.asn\tresp_netblock.org",
+				"#types\ttime\taddr\tport\taddr\tport\tcount\tstring\tcount\tstring",
+				"1665400000.000000\t203.0.113.10\t51000\t2001:db8::1\t443\t64500\tExample B.V.\t-\t(empty)",
+				"1665400001.000000\t198.51.100.1\t51001\t203.0.113.200\t80\t-\t-\t-\tExample B.V.",
+				"#close\t2022-10-10-12-00-00",
+			},
+			stats: Stats{Lines: 10, Enriched: 2},
+		},
+		{
+			name:   "tsv without addresses",
+			params: ZeekParams{Fields: []Field{FieldASN}},
+			log: []string{
+				"#separator ,",
+				"#fields,ts,fuid,tx_hosts",
+				"#types,time,string,set[addr]",
+				"1665400000.000000,FhZ1,203.0.113.10",
+			},
+			want: []string{
+				"#separator ,",
+				"#fields,ts,fuid,tx_hosts",
+				"#types,time,string,set[addr]",
+				"1665400000.000000,FhZ1,203.0.113.10",
+			},
+			stats: Stats{Lines: 4},
+		},
+		{
+			name:   "json",
+			params: ZeekParams{Fields: []Field{FieldASN, FieldCountry}, OrigNamespace: "orig", RespNamespace: "resp"},
+			log: []string{
+				`{"ts":1665400000.0,"id.orig_h":"203.0.113.10","id.resp_h":"2001:db8::1","id.resp_p":443}`,
+				`{"ts":1665400001.0,"id.orig_h":"198.51.100.1","id.resp_h":"fe80::1"}`,
+				`{"ts":`,
+			},
+			want: []string{
+				`{"ts":1665400000.0,"id.orig_h":"203.0.113.10","id.resp_h":"2001:db8::1","id.resp_p":443,` +
+					`"orig.asn":64500,"orig.country":"NL","resp.country":"US"}`,
+				`{"ts":1665400001.0,"id.orig_h":"198.51.100.1","id.resp_h":"fe80::1"}`,
+				`{"ts":`,
+			},
+			stats: Stats{Lines: 3, Enriched: 1, Unparsed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := NewZeek(NewResolver(newFakeService(), ResolverParams{}), tt.params)
+
+			var b bytes.Buffer
+			stats, err := z.Enrich(context.Background(), strings.NewReader(strings.Join(tt.log, "\n")+"\n"), &b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := b.String(), strings.Join(tt.want, "\n")+"\n"; got != want {
+				t.Errorf("Enrich() = %s, want %s", got, want)
+			}
+
+			if *stats != tt.stats {
+				t.Errorf("Enrich() stats = %+v, want %+v", *stats, tt.stats)
+			}
+		})
+	}
+}
+
+// TestEscapeZeek tests the escapeZeek and unescapeZeek functions.
+func TestEscapeZeek(t *testing.T) {
+	if got := escapeZeek("A\tB\\C\n", "\t"); got != `A\x09B\x5cC\x0a` {
+		t.Errorf("escapeZeek() = %s", got)
+	}
+
+	if got := unescapeZeek(`\x09`); got != "\t" {
+		t.Errorf("unescapeZeek() = %q", got)
+	}
+
+	if got := unescapeZeek(`\x0`); got != `\x0` {
+		t.Errorf("unescapeZeek() = %q", got)
+	}
+}
+
+// TestZeekEnrichOrder tests that netblocks of addresses don't depend on the order of log lines.
+func TestZeekEnrichOrder(t *testing.T) {
+	lines := []string{
+		`{"id.orig_h":"203.0.113.200","id.resp_h":"203.0.113.10"}`,
+		`{"id.orig_h":"203.0.113.10","id.resp_h":"203.0.113.200"}`,
+		`{"id.orig_h":"203.0.113.127","id.resp_h":"203.0.113.128"}`,
+	}
+
+	want := []string{
+		`{"id.orig_h":"203.0.113.200","id.resp_h":"203.0.113.10",` +
+			`"orig_netblock.netname":"EXAMPLE-NET",` +
+			`"resp_netblock.asn":64500,"resp_netblock.netname":"EXAMPLE-NET"}`,
+		`{"id.orig_h":"203.0.113.10","id.resp_h":"203.0.113.200",` +
+			`"orig_netblock.asn":64500,"orig_netblock.netname":"EXAMPLE-NET",` +
+			`"resp_netblock.netname":"EXAMPLE-NET"}`,
+		`{"id.orig_h":"203.0.113.127","id.resp_h":"203.0.113.128",` +
+			`"orig_netblock.asn":64500,"orig_netblock.netname":"EXAMPLE-NET",` +
+			`"resp_netblock.netname":"EXAMPLE-NET"}`,
+	}
+
+	z := NewZeek(NewResolver(newFakeService(), ResolverParams{}), ZeekParams{Fields: []Field{FieldASN, FieldNetname}})
+
+	var b bytes.Buffer
+	if _, err := z.Enrich(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := b.String(), strings.Join(want, "\n")+"\n"; got != want {
+		t.Errorf("Enrich() = %s, want %s", got, want)
+	}
+}
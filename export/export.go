@@ -0,0 +1,298 @@
+// Package export converts IP netblocks to address lists for firewalls, routers and other consumers.
+package export
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Source identifies a netblock a block was built from.
+type Source struct {
+	// Netname is the name of the netblock.
+	Netname string
+
+	// ASN is the autonomous system number of the netblock. Zero when unknown.
+	ASN int
+
+	// Registry is the source registry of the netblock.
+	Registry ipnetblocks.Registry
+
+	// Modified is the time when the netblock was modified the last time.
+	Modified time.Time
+}
+
+// String returns the source as "NETNAME AS123".
+func (s Source) String() string {
+	switch {
+	case s.ASN == 0:
+		return s.Netname
+	case s.Netname == "":
+		return "AS" + strconv.Itoa(s.ASN)
+	default:
+		return s.Netname + " AS" + strconv.Itoa(s.ASN)
+	}
+}
+
+// Block is a continuous address range merged from overlapping and adjacent netblocks.
+type Block struct {
+	// Range is the merged address range.
+	Range ipnetblocks.Range
+
+	// Prefixes is the minimal list of prefixes covering the range.
+	Prefixes []netip.Prefix
+
+	// Sources is the sorted list of unique netblocks the block was built from.
+	Sources []Source
+
+	// entries is the list of netblock ranges the block was built from.
+	entries []entry
+}
+
+// Comment returns the sources of the block joined into a single line.
+func (b Block) Comment() string {
+	return sourcesComment(b.Sources)
+}
+
+// prefixSources returns the sorted list of unique netblocks overlapping the prefix of the block.
+func (b Block) prefixSources(prefix netip.Prefix) []Source {
+	if b.entries == nil {
+		return b.Sources
+	}
+
+	pr := ipnetblocks.PrefixRange(prefix)
+
+	var sources []Source
+	for _, e := range b.entries {
+		if !e.r.Last.Less(pr.First) && !pr.Last.Less(e.r.First) {
+			sources = append(sources, e.source)
+		}
+	}
+
+	return sortSources(sources)
+}
+
+// Blocks is the list of merged blocks split by family, in address order.
+type Blocks struct {
+	// IPv4 is the list of IPv4 blocks.
+	IPv4 []Block
+
+	// IPv6 is the list of IPv6 blocks.
+	IPv6 []Block
+}
+
+// Prefixes returns prefixes of all blocks of the list.
+func Prefixes(blocks []Block) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, block := range blocks {
+		prefixes = append(prefixes, block.Prefixes...)
+	}
+
+	return prefixes
+}
+
+// Aggregate merges overlapping and adjacent netblocks and splits the result by family.
+func Aggregate(inetnums []ipnetblocks.Inetnum) (Blocks, error) {
+	entries := make([]entry, 0, len(inetnums))
+	for _, inetnum := range inetnums {
+		r, err := inetnum.Range()
+		if err != nil {
+			return Blocks{}, fmt.Errorf("cannot parse netblock %q: %w", inetnum.Inetnum, err)
+		}
+
+		entries = append(entries, entry{r: r, source: inetnumSource(inetnum)})
+	}
+
+	return aggregate(entries), nil
+}
+
+// entry is an address range with the netblock it was taken from.
+type entry struct {
+	r      ipnetblocks.Range
+	source Source
+}
+
+// inetnumSource returns the source of the netblock.
+func inetnumSource(inetnum ipnetblocks.Inetnum) Source {
+	return Source{
+		Netname:  inetnum.Netname,
+		ASN:      inetnum.AS.ASN,
+		Registry: inetnum.Registry(),
+		Modified: time.Time(inetnum.Modified),
+	}
+}
+
+// aggregate merges overlapping and adjacent ranges and splits the result by family.
+func aggregate(entries []entry) Blocks {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].r.First.Less(entries[j].r.First)
+	})
+
+	var blocks Blocks
+	var current *Block
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		current.Prefixes = current.Range.Prefixes()
+		current.Sources = sortSources(current.Sources)
+
+		if current.Range.First.Is4() {
+			blocks.IPv4 = append(blocks.IPv4, *current)
+		} else {
+			blocks.IPv6 = append(blocks.IPv6, *current)
+		}
+		current = nil
+	}
+
+	for _, e := range entries {
+		if current != nil && adjoins(current.Range, e.r) {
+			if current.Range.Last.Less(e.r.Last) {
+				current.Range.Last = e.r.Last
+			}
+			current.Sources = append(current.Sources, e.source)
+			current.entries = append(current.entries, e)
+			continue
+		}
+
+		flush()
+		current = &Block{Range: e.r, Sources: []Source{e.source}, entries: []entry{e}}
+	}
+	flush()
+
+	return blocks
+}
+
+// adjoins reports whether the range starting not before a overlaps or directly follows it.
+func adjoins(a, b ipnetblocks.Range) bool {
+	if a.First.BitLen() != b.First.BitLen() {
+		return false
+	}
+
+	next := a.Last.Next()
+
+	return !next.IsValid() || !next.Less(b.First)
+}
+
+// sortSources sorts sources and removes duplicates.
+func sortSources(sources []Source) []Source {
+	sort.Slice(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		switch {
+		case a.Netname != b.Netname:
+			return a.Netname < b.Netname
+		case a.ASN != b.ASN:
+			return a.ASN < b.ASN
+		case a.Registry != b.Registry:
+			return a.Registry < b.Registry
+		default:
+			return a.Modified.Before(b.Modified)
+		}
+	})
+
+	unique := sources[:0]
+	for i, source := range sources {
+		if i == 0 || source != sources[i-1] {
+			unique = append(unique, source)
+		}
+	}
+
+	return unique
+}
+
+// sourcesComment returns the sources joined into a single line.
+func sourcesComment(sources []Source) string {
+	return sanitizeComment(strings.Join(sourceNames(sources), ", "))
+}
+
+// sourcesCommentLimit returns the sources joined into a single line of at most maxLen bytes. Sources which don't
+// fit are counted as "+N more".
+func sourcesCommentLimit(sources []Source, maxLen int) string {
+	names := sourceNames(sources)
+	for i, name := range names {
+		names[i] = sanitizeComment(name)
+	}
+
+	return joinLimit(names, maxLen)
+}
+
+// joinLimit returns the names joined with commas into at most maxLen bytes. Names which don't fit are counted
+// as "+N more".
+func joinLimit(names []string, maxLen int) string {
+	var b strings.Builder
+	for i, name := range names {
+		more := ""
+		if rest := len(names) - i - 1; rest > 0 {
+			more = fmt.Sprintf(", +%d more", rest)
+		}
+
+		separator := ""
+		if i > 0 {
+			separator = ", "
+		}
+
+		if b.Len()+len(separator)+len(name)+len(more) <= maxLen {
+			b.WriteString(separator + name)
+			continue
+		}
+
+		if i > 0 {
+			// The previous name was only taken when this suffix fits after it.
+			fmt.Fprintf(&b, ", +%d more", len(names)-i)
+			break
+		}
+
+		return cutString(name, maxLen-len("...")-len(more)) + "..." + more
+	}
+
+	return b.String()
+}
+
+// sourceNames returns the unique names of the sorted sources.
+func sourceNames(sources []Source) []string {
+	names := make([]string, 0, len(sources))
+	for i, source := range sources {
+		if i > 0 && source.String() == sources[i-1].String() {
+			continue
+		}
+		names = append(names, source.String())
+	}
+
+	return names
+}
+
+// cutString returns the prefix of the string of at most n bytes, cut on a rune boundary.
+func cutString(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// sanitizeComment makes the text safe to put into a single-line comment.
+func sanitizeComment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == '"' || r == 0x7f {
+			return ' '
+		}
+
+		return r
+	}, s)
+}
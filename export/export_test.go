@@ -0,0 +1,53 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// testInetnums is the sample list of netblocks for testing.
+var testInetnums = []ipnetblocks.Inetnum{
+	{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8", AS: ipnetblocks.AS{ASN: 15169}},
+	{Inetnum: "2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff", Netname: "GOOGLE-IPV6", AS: ipnetblocks.AS{ASN: 15169}},
+	{Inetnum: "8.8.4.0 - 8.8.4.255", Netname: "GOGL", AS: ipnetblocks.AS{ASN: 15169}},
+	{Inetnum: "8.8.9.0 - 8.8.9.127", Netname: "EXAMPLE"},
+	{Inetnum: "8.8.8.0 - 8.8.8.127", Netname: "LVLT-GOGL-8-8-8", AS: ipnetblocks.AS{ASN: 15169}},
+}
+
+// TestAggregate tests the Aggregate function.
+func TestAggregate(t *testing.T) {
+	blocks, err := Aggregate(testInetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, block := range blocks.IPv4 {
+		got = append(got, block.Range.String()+" "+block.Comment())
+	}
+	for _, block := range blocks.IPv6 {
+		got = append(got, block.Range.String()+" "+block.Comment())
+	}
+
+	want := []string{
+		"8.8.4.0 - 8.8.4.255 GOGL AS15169",
+		"8.8.8.0 - 8.8.9.127 EXAMPLE, LVLT-GOGL-8-8-8 AS15169",
+		"2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff GOOGLE-IPV6 AS15169",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Aggregate() = %v, want %v", got, want)
+	}
+
+	_, err = Aggregate([]ipnetblocks.Inetnum{{Inetnum: "invalid"}})
+	checkErr(t, err, `cannot parse netblock "invalid": invalid argument: "invalid" is invalid IP range`)
+}
+
+// checkErr checks for an error.
+func checkErr(t *testing.T, err error, want string) {
+	if (err != nil || want != "") && (err == nil || err.Error() != want) {
+		t.Errorf("error = %v, wantErr %v", err, want)
+	}
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// NFTablesParams is used to render nftables sets. None of parameters are mandatory.
+type NFTablesParams struct {
+	// Family is the table family. Default: "inet".
+	Family string
+
+	// Table is the table name. Default: "filter".
+	Table string
+
+	// Set is the base name of the sets, suffixed with "_v4" and "_v6". Default: "netblocks".
+	Set string
+}
+
+// NFTables writes nftables interval sets of IPv4 and IPv6 blocks, loadable with "nft -f".
+func NFTables(w io.Writer, blocks Blocks, params NFTablesParams) error {
+	family := defaults.String(params.Family, "inet")
+	table := defaults.String(params.Table, "filter")
+	set := defaults.String(params.Set, "netblocks")
+
+	var b bytes.Buffer
+
+	b.WriteString(header)
+	fmt.Fprintf(&b, "table %s %s {\n", family, table)
+	writeNFTablesSet(&b, set+"_v4", "ipv4_addr", blocks.IPv4)
+	writeNFTablesSet(&b, set+"_v6", "ipv6_addr", blocks.IPv6)
+	b.WriteString("}\n")
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// writeNFTablesSet writes a single nftables set definition.
+func writeNFTablesSet(b *bytes.Buffer, name, typ string, blocks []Block) {
+	fmt.Fprintf(b, "\tset %s {\n", name)
+	fmt.Fprintf(b, "\t\ttype %s\n", typ)
+	b.WriteString("\t\tflags interval\n")
+
+	if len(blocks) > 0 {
+		b.WriteString("\t\telements = {\n")
+
+		for i, block := range blocks {
+			fmt.Fprintf(b, "\t\t\t# %s\n", block.Comment())
+
+			for j, prefix := range block.Prefixes {
+				separator := ","
+				if i == len(blocks)-1 && j == len(block.Prefixes)-1 {
+					separator = ""
+				}
+
+				fmt.Fprintf(b, "\t\t\t%s%s\n", prefix, separator)
+			}
+		}
+
+		b.WriteString("\t\t}\n")
+	}
+
+	b.WriteString("\t}\n")
+}
+
+// IPSetParams is used to render ipset restore files. None of parameters are mandatory.
+type IPSetParams struct {
+	// Set is the base name of the sets, suffixed with "-v4" and "-v6". Default: "netblocks".
+	Set string
+}
+
+// IPSet writes hash:net sets of IPv4 and IPv6 blocks, loadable with "ipset restore".
+// Each entry is commented with its sources.
+func IPSet(w io.Writer, blocks Blocks, params IPSetParams) error {
+	set := defaults.String(params.Set, "netblocks")
+
+	var b bytes.Buffer
+
+	writeIPSet(&b, set+"-v4", "inet", blocks.IPv4)
+	writeIPSet(&b, set+"-v6", "inet6", blocks.IPv6)
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// maxIPSetComment is the maximum length of ipset entry comments, IPSET_MAX_COMMENT_SIZE.
+const maxIPSetComment = 255
+
+// writeIPSet writes a single ipset definition with its entries. Comments are cut to the ipset limit, as ipset
+// restore rejects the whole set otherwise.
+func writeIPSet(b *bytes.Buffer, name, family string, blocks []Block) {
+	maxElem := 65536
+	if n := len(Prefixes(blocks)); n > maxElem {
+		maxElem = n
+	}
+
+	fmt.Fprintf(b, "create %s hash:net family %s maxelem %d comment -exist\n", name, family, maxElem)
+
+	for _, block := range blocks {
+		comment := strconv.Quote(sourcesCommentLimit(block.Sources, maxIPSetComment))
+		for _, prefix := range block.Prefixes {
+			fmt.Fprintf(b, "add %s %s comment %s -exist\n", name, prefix, comment)
+		}
+	}
+}
+
+// PF writes a pf table file with IPv4 and IPv6 blocks, loadable with "table <name> persist file".
+func PF(w io.Writer, blocks Blocks) error {
+	var b bytes.Buffer
+
+	b.WriteString(header)
+
+	for _, list := range [][]Block{blocks.IPv4, blocks.IPv6} {
+		for _, block := range list {
+			fmt.Fprintf(&b, "# %s\n", block.Comment())
+
+			for _, prefix := range block.Prefixes {
+				fmt.Fprintf(&b, "%s\n", prefix)
+			}
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// header is the comment put at the beginning of generated files.
+const header = "# Generated by ip-netblocks-go. Do not edit.\n"
@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// TestFirewall tests the NFTables, IPSet and PF functions.
+func TestFirewall(t *testing.T) {
+	blocks, err := Aggregate(testInetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		render func(b *bytes.Buffer) error
+		want   string
+	}{
+		{
+			name: "nftables",
+			render: func(b *bytes.Buffer) error {
+				return NFTables(b, blocks, NFTablesParams{Set: "google"})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+table inet filter {
+	set google_v4 {
+		type ipv4_addr
+		flags interval
+		elements = {
+			# GOGL AS15169
+			8.8.4.0/24,
+			# EXAMPLE, LVLT-GOGL-8-8-8 AS15169
+			8.8.8.0/24,
+			8.8.9.0/25
+		}
+	}
+	set google_v6 {
+		type ipv6_addr
+		flags interval
+		elements = {
+			# GOOGLE-IPV6 AS15169
+			2001:4860::/32
+		}
+	}
+}
+`,
+		},
+		{
+			name: "ipset",
+			render: func(b *bytes.Buffer) error {
+				return IPSet(b, blocks, IPSetParams{})
+			},
+			want: `create netblocks-v4 hash:net family inet maxelem 65536 comment -exist
+add netblocks-v4 8.8.4.0/24 comment "GOGL AS15169" -exist
+add netblocks-v4 8.8.8.0/24 comment "EXAMPLE, LVLT-GOGL-8-8-8 AS15169" -exist
+add netblocks-v4 8.8.9.0/25 comment "EXAMPLE, LVLT-GOGL-8-8-8 AS15169" -exist
+create netblocks-v6 hash:net family inet6 maxelem 65536 comment -exist
+add netblocks-v6 2001:4860::/32 comment "GOOGLE-IPV6 AS15169" -exist
+`,
+		},
+		{
+			name: "pf",
+			render: func(b *bytes.Buffer) error {
+				return PF(b, blocks)
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+# GOGL AS15169
+8.8.4.0/24
+# EXAMPLE, LVLT-GOGL-8-8-8 AS15169
+8.8.8.0/24
+8.8.9.0/25
+# GOOGLE-IPV6 AS15169
+2001:4860::/32
+`,
+		},
+		{
+			name: "empty nftables",
+			render: func(b *bytes.Buffer) error {
+				return NFTables(b, Blocks{}, NFTablesParams{Family: "ip6", Table: "fw", Set: "empty"})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+table ip6 fw {
+	set empty_v4 {
+		type ipv4_addr
+		flags interval
+	}
+	set empty_v6 {
+		type ipv6_addr
+		flags interval
+	}
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.render(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// manyInetnums returns adjacent netblocks with long names, which are merged into a single block.
+func manyInetnums(n int) []ipnetblocks.Inetnum {
+	inetnums := make([]ipnetblocks.Inetnum, 0, n)
+	for i := 0; i < n; i++ {
+		inetnums = append(inetnums, ipnetblocks.Inetnum{
+			Inetnum: fmt.Sprintf("10.0.%d.0 - 10.0.%d.255", i, i),
+			Netname: fmt.Sprintf("EXAMPLE-NETWORK-OPERATOR-%02d", i),
+			AS:      ipnetblocks.AS{ASN: 64500 + i},
+		})
+	}
+
+	return inetnums
+}
+
+// TestIPSetComments tests that IPSet cuts comments to the ipset limit.
+func TestIPSetComments(t *testing.T) {
+	blocks, err := Aggregate(manyInetnums(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err = IPSet(&b, blocks, IPSetParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var adds int
+	for _, line := range strings.Split(b.String(), "\n") {
+		if !strings.HasPrefix(line, "add ") {
+			continue
+		}
+		adds++
+
+		quoted := line[strings.Index(line, ` comment "`)+len(" comment ") : strings.LastIndex(line, " -exist")]
+		comment, err := strconv.Unquote(quoted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(comment) > maxIPSetComment || !strings.HasPrefix(comment, "EXAMPLE-NETWORK-OPERATOR-00 AS64500, ") ||
+			!strings.HasSuffix(comment, " more") {
+			t.Errorf("comment = %q (%d bytes)", comment, len(comment))
+		}
+	}
+
+	if adds == 0 {
+		t.Errorf("IPSet() got no entries:\n%s", b.String())
+	}
+
+	long := []Source{{Netname: strings.Repeat("É", 200)}, {Netname: "B"}}
+	if got := sourcesCommentLimit(long, 255); len(got) > 255 || !utf8.ValidString(got) ||
+		!strings.HasSuffix(got, "..., +1 more") {
+		t.Errorf("sourcesCommentLimit() = %q (%d bytes)", got, len(got))
+	}
+
+	if got := sourcesCommentLimit(long[1:], 255); got != "B" {
+		t.Errorf("sourcesCommentLimit() = %q", got)
+	}
+}
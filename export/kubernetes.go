@@ -0,0 +1,235 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// annotationPrefix is the prefix of provenance annotations put on generated objects.
+const annotationPrefix = "ip-netblocks.whoisxmlapi.com/"
+
+// maxAnnotation is the maximum length of list annotations, so that many netblocks don't bloat the object.
+const maxAnnotation = 1024
+
+// NetworkPolicyParams is used to render Kubernetes network policies. None of parameters are mandatory.
+type NetworkPolicyParams struct {
+	// Name is the name of the policy. Default: "netblocks".
+	Name string
+
+	// Namespace is the namespace of the policy. Omitted when empty.
+	Namespace string
+
+	// PodSelector is the set of labels selecting pods the policy applies to. Empty selects all pods.
+	PodSelector map[string]string
+
+	// Except is the list of prefixes carved out of the allowed blocks.
+	Except []netip.Prefix
+
+	// Ingress makes the policy allow incoming traffic from the blocks instead of outgoing traffic to them.
+	Ingress bool
+}
+
+// NetworkPolicy writes the networking.k8s.io/v1 NetworkPolicy which allows traffic to the blocks.
+func NetworkPolicy(w io.Writer, blocks Blocks, params NetworkPolicyParams) error {
+	direction, peer, policyType := "egress", "to", "Egress"
+	if params.Ingress {
+		direction, peer, policyType = "ingress", "from", "Ingress"
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString(header)
+	b.WriteString("apiVersion: networking.k8s.io/v1\n")
+	b.WriteString("kind: NetworkPolicy\n")
+	writeMetadata(&b, blocks, params)
+	b.WriteString("spec:\n")
+	writeSelector(&b, "podSelector", params.PodSelector)
+	fmt.Fprintf(&b, "  policyTypes:\n  - %s\n", policyType)
+	rules := cidrRules(blocks, params.Except)
+	if len(rules) == 0 {
+		// A peer without ipBlocks would allow everything, so no rules are written instead.
+		fmt.Fprintf(&b, "  %s: []\n", direction)
+	} else {
+		fmt.Fprintf(&b, "  %s:\n", direction)
+		fmt.Fprintf(&b, "  - %s:\n", peer)
+	}
+
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "    # %s\n", rule.comment)
+		fmt.Fprintf(&b, "    - ipBlock:\n        cidr: %s\n", rule.prefix)
+		writeExcept(&b, "        ", rule.except)
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// CiliumNetworkPolicy writes the cilium.io/v2 CiliumNetworkPolicy which allows traffic to the blocks.
+func CiliumNetworkPolicy(w io.Writer, blocks Blocks, params NetworkPolicyParams) error {
+	direction, peer := "egress", "toCIDRSet"
+	if params.Ingress {
+		direction, peer = "ingress", "fromCIDRSet"
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString(header)
+	b.WriteString("apiVersion: cilium.io/v2\n")
+	b.WriteString("kind: CiliumNetworkPolicy\n")
+	writeMetadata(&b, blocks, params)
+	b.WriteString("spec:\n")
+	writeSelector(&b, "endpointSelector", params.PodSelector)
+	fmt.Fprintf(&b, "  %s:\n", direction)
+
+	rules := cidrRules(blocks, params.Except)
+	if len(rules) == 0 {
+		// An empty rule enables the default deny without allowing anything.
+		b.WriteString("  - {}\n")
+	} else {
+		fmt.Fprintf(&b, "  - %s:\n", peer)
+	}
+
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "    # %s\n", rule.comment)
+		fmt.Fprintf(&b, "    - cidr: %s\n", rule.prefix)
+		writeExcept(&b, "      ", rule.except)
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// cidrRule is an allowed prefix with its carve-outs.
+type cidrRule struct {
+	prefix  netip.Prefix
+	except  []netip.Prefix
+	comment string
+}
+
+// cidrRules returns allowed prefixes of the blocks with carve-outs which belong to them, commented with
+// the netblocks overlapping each prefix.
+// Prefixes entirely covered by a carve-out are left out.
+func cidrRules(blocks Blocks, except []netip.Prefix) []cidrRule {
+	var rules []cidrRule
+
+	for _, list := range [][]Block{blocks.IPv4, blocks.IPv6} {
+		for _, block := range list {
+		prefixes:
+			for _, prefix := range block.Prefixes {
+				rule := cidrRule{prefix: prefix, comment: sourcesComment(block.prefixSources(prefix))}
+
+				for _, e := range except {
+					e = e.Masked()
+					switch {
+					case e.Bits() <= prefix.Bits() && e.Contains(prefix.Addr()):
+						continue prefixes
+					case prefix.Contains(e.Addr()):
+						rule.except = append(rule.except, e)
+					}
+				}
+
+				sort.Slice(rule.except, func(i, j int) bool {
+					return routeLess(Route{Prefix: rule.except[i]}, Route{Prefix: rule.except[j]})
+				})
+
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+// writeMetadata writes the object metadata with provenance annotations.
+func writeMetadata(b *bytes.Buffer, blocks Blocks, params NetworkPolicyParams) {
+	b.WriteString("metadata:\n")
+	fmt.Fprintf(b, "  name: %s\n", defaults.String(params.Name, "netblocks"))
+
+	if params.Namespace != "" {
+		fmt.Fprintf(b, "  namespace: %s\n", params.Namespace)
+	}
+
+	netnames := make(map[string]bool)
+	registries := make(map[string]bool)
+	var modified time.Time
+
+	for _, list := range [][]Block{blocks.IPv4, blocks.IPv6} {
+		for _, block := range list {
+			for _, source := range block.Sources {
+				if source.Netname != "" {
+					netnames[source.Netname] = true
+				}
+				if source.Registry != "" {
+					registries[string(source.Registry)] = true
+				}
+				if source.Modified.After(modified) {
+					modified = source.Modified
+				}
+			}
+		}
+	}
+
+	b.WriteString("  annotations:\n")
+	for _, annotation := range []struct {
+		name string
+		set  map[string]bool
+	}{{"netnames", netnames}, {"sources", registries}} {
+		value := joinLimit(sortedKeys(annotation.set), maxAnnotation)
+		fmt.Fprintf(b, "    %s%s: %s\n", annotationPrefix, annotation.name, strconv.Quote(value))
+	}
+	if !modified.IsZero() {
+		fmt.Fprintf(b, "    %smodified: %s\n", annotationPrefix, strconv.Quote(modified.UTC().Format(time.RFC3339)))
+	}
+}
+
+// writeSelector writes the label selector, an empty one selects everything.
+func writeSelector(b *bytes.Buffer, name string, labels map[string]string) {
+	if len(labels) == 0 {
+		fmt.Fprintf(b, "  %s: {}\n", name)
+		return
+	}
+
+	fmt.Fprintf(b, "  %s:\n    matchLabels:\n", name)
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(b, "      %s: %s\n", strconv.Quote(key), strconv.Quote(labels[key]))
+	}
+}
+
+// writeExcept writes the list of carve-outs, if any.
+func writeExcept(b *bytes.Buffer, indent string, except []netip.Prefix) {
+	if len(except) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "%sexcept:\n", indent)
+	for _, prefix := range except {
+		fmt.Fprintf(b, "%s- %s\n", indent, prefix)
+	}
+}
+
+// sortedKeys returns sorted keys of the set.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// TestNetworkPolicies tests the NetworkPolicy and CiliumNetworkPolicy functions.
+func TestNetworkPolicies(t *testing.T) {
+	blocks, err := Aggregate([]ipnetblocks.Inetnum{
+		{
+			Inetnum:  "8.8.8.0 - 8.8.8.255",
+			Netname:  "LVLT-GOGL-8-8-8",
+			AS:       ipnetblocks.AS{ASN: 15169},
+			Source:   "ARIN",
+			Modified: ipnetblocks.Time(time.Date(2014, 3, 14, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			Inetnum: "8.8.4.0 - 8.8.4.255",
+			Netname: "GOGL",
+			AS:      ipnetblocks.AS{ASN: 15169},
+			Source:  "ARIN",
+		},
+		{
+			Inetnum: "2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff",
+			Netname: "GOOGLE-IPV6",
+			Source:  "ARIN",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := NetworkPolicyParams{
+		Name:        "allow-google",
+		Namespace:   "default",
+		PodSelector: map[string]string{"app": "web", "tier": "frontend"},
+		Except:      []netip.Prefix{netip.MustParsePrefix("8.8.8.128/25"), netip.MustParsePrefix("8.8.4.0/23")},
+	}
+
+	tests := []struct {
+		name   string
+		render func(b *bytes.Buffer) error
+		want   string
+	}{
+		{
+			name: "network policy",
+			render: func(b *bytes.Buffer) error {
+				return NetworkPolicy(b, blocks, params)
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-google
+  namespace: default
+  annotations:
+    ip-netblocks.whoisxmlapi.com/netnames: "GOGL, GOOGLE-IPV6, LVLT-GOGL-8-8-8"
+    ip-netblocks.whoisxmlapi.com/sources: "ARIN"
+    ip-netblocks.whoisxmlapi.com/modified: "2014-03-14T00:00:00Z"
+spec:
+  podSelector:
+    matchLabels:
+      "app": "web"
+      "tier": "frontend"
+  policyTypes:
+  - Egress
+  egress:
+  - to:
+    # LVLT-GOGL-8-8-8 AS15169
+    - ipBlock:
+        cidr: 8.8.8.0/24
+        except:
+        - 8.8.8.128/25
+    # GOOGLE-IPV6
+    - ipBlock:
+        cidr: 2001:4860::/32
+`,
+		},
+		{
+			name: "cilium network policy",
+			render: func(b *bytes.Buffer) error {
+				return CiliumNetworkPolicy(b, blocks, NetworkPolicyParams{Ingress: true, Except: params.Except})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: netblocks
+  annotations:
+    ip-netblocks.whoisxmlapi.com/netnames: "GOGL, GOOGLE-IPV6, LVLT-GOGL-8-8-8"
+    ip-netblocks.whoisxmlapi.com/sources: "ARIN"
+    ip-netblocks.whoisxmlapi.com/modified: "2014-03-14T00:00:00Z"
+spec:
+  endpointSelector: {}
+  ingress:
+  - fromCIDRSet:
+    # LVLT-GOGL-8-8-8 AS15169
+    - cidr: 8.8.8.0/24
+      except:
+      - 8.8.8.128/25
+    # GOOGLE-IPV6
+    - cidr: 2001:4860::/32
+`,
+		},
+		{
+			name: "empty network policy",
+			render: func(b *bytes.Buffer) error {
+				return NetworkPolicy(b, Blocks{}, NetworkPolicyParams{})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: netblocks
+  annotations:
+    ip-netblocks.whoisxmlapi.com/netnames: ""
+    ip-netblocks.whoisxmlapi.com/sources: ""
+spec:
+  podSelector: {}
+  policyTypes:
+  - Egress
+  egress: []
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.render(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNetworkPolicyComments tests that rules are commented with their own netblocks and annotations are capped.
+func TestNetworkPolicyComments(t *testing.T) {
+	blocks, err := Aggregate(manyInetnums(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err = NetworkPolicy(&b, blocks, NetworkPolicyParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+
+	// 10.0.32.0/21 only covers the last eight netblocks.
+	var names []string
+	for i := 32; i < 40; i++ {
+		names = append(names, fmt.Sprintf("EXAMPLE-NETWORK-OPERATOR-%d AS%d", i, 64500+i))
+	}
+
+	want := "    # " + strings.Join(names, ", ") + "\n    - ipBlock:\n        cidr: 10.0.32.0/21\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("NetworkPolicy() got:\n%s\nwant rule:\n%s", out, want)
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.Contains(line, annotationPrefix+"netnames: ") {
+			continue
+		}
+
+		value, err := strconv.Unquote(line[strings.Index(line, ": ")+2:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(value) > maxAnnotation || !strings.HasSuffix(value, " more") {
+			t.Errorf("netnames annotation = %q (%d bytes)", value, len(value))
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/cidrset"
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// Route is a routed prefix with the netblocks it was announced for.
+type Route struct {
+	// Prefix is the routed prefix.
+	Prefix netip.Prefix
+
+	// Sources is the sorted list of unique netblocks the route was taken from.
+	Sources []Source
+}
+
+// Routes is the list of routes split by family, in address order.
+type Routes struct {
+	// IPv4 is the list of IPv4 routes.
+	IPv4 []Route
+
+	// IPv6 is the list of IPv6 routes.
+	IPv6 []Route
+}
+
+// CollectRoutes returns unique routes of the netblocks. The AS.Route field is used when it's set, otherwise the
+// netblock range is split into prefixes.
+func CollectRoutes(inetnums []ipnetblocks.Inetnum) (Routes, error) {
+	sources := make(map[netip.Prefix][]Source)
+
+	for _, inetnum := range inetnums {
+		var prefixes []netip.Prefix
+
+		if inetnum.AS.Route != "" {
+			prefix, err := netip.ParsePrefix(inetnum.AS.Route)
+			if err != nil {
+				return Routes{}, fmt.Errorf("cannot parse route %q: %w", inetnum.AS.Route, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		} else {
+			r, err := inetnum.Range()
+			if err != nil {
+				return Routes{}, fmt.Errorf("cannot parse netblock %q: %w", inetnum.Inetnum, err)
+			}
+			prefixes = r.Prefixes()
+		}
+
+		for _, prefix := range prefixes {
+			sources[prefix] = append(sources[prefix], inetnumSource(inetnum))
+		}
+	}
+
+	var routes Routes
+	for prefix, prefixSources := range sources {
+		route := Route{Prefix: prefix, Sources: sortSources(prefixSources)}
+		if prefix.Addr().Is4() {
+			routes.IPv4 = append(routes.IPv4, route)
+		} else {
+			routes.IPv6 = append(routes.IPv6, route)
+		}
+	}
+
+	sortRoutes(routes.IPv4)
+	sortRoutes(routes.IPv6)
+
+	return routes, nil
+}
+
+// PrefixBounds is the range of accepted prefix lengths for each entry of a prefix list.
+// Zero values mean the length of the entry's prefix, i.e. the exact match. Values are capped at the bit length
+// of the family.
+type PrefixBounds struct {
+	// GE is the minimum accepted prefix length.
+	GE int
+
+	// LE is the maximum accepted prefix length.
+	LE int
+}
+
+// bounds returns the accepted prefix lengths for the prefix.
+func (b PrefixBounds) bounds(prefix netip.Prefix) (ge, le int) {
+	ge, le = prefix.Bits(), prefix.Bits()
+
+	if b.GE > ge {
+		ge = b.GE
+	}
+
+	if b.LE > le {
+		le = b.LE
+	}
+
+	if max := prefix.Addr().BitLen(); ge > max {
+		ge = max
+	}
+
+	if max := prefix.Addr().BitLen(); le > max {
+		le = max
+	}
+
+	if le < ge {
+		le = ge
+	}
+
+	return ge, le
+}
+
+// PrefixListParams is used to render prefix lists. None of parameters are mandatory.
+type PrefixListParams struct {
+	// Name is the base name of the lists, suffixed with the family. Default: "NETBLOCKS".
+	Name string
+
+	// IPv4 is the range of accepted prefix lengths for IPv4 entries.
+	IPv4 PrefixBounds
+
+	// IPv6 is the range of accepted prefix lengths for IPv6 entries.
+	IPv6 PrefixBounds
+}
+
+// prefixListEntry is a single rendered entry of a prefix list.
+type prefixListEntry struct {
+	route  Route
+	ge, le int
+}
+
+// prefixList returns entries of the prefix list with adjacent routes aggregated and routes accepted by wider
+// entries removed.
+func prefixList(routes []Route, bounds PrefixBounds) []prefixListEntry {
+	var entries []prefixListEntry
+
+	for _, e := range aggregateRoutes(routes, bounds) {
+		covered := false
+		for _, wider := range entries {
+			if wider.route.Prefix.Contains(e.route.Prefix.Addr()) && wider.ge <= e.ge && e.le <= wider.le &&
+				wider.route.Prefix.Bits() <= e.route.Prefix.Bits() {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return routeLess(entries[i].route, entries[j].route)
+	})
+
+	return entries
+}
+
+// aggregateRoutes returns entries for the routes, with adjacent routes accepting the same prefix lengths merged
+// into wider entries, ordered by prefix length. Lengths accepted by a merged entry are at least the ones of its
+// routes, so it accepts exactly what they do, e.g. 10.0.0.0/25 and 10.0.0.128/25 become 10.0.0.0/24{25,25}.
+func aggregateRoutes(routes []Route, bounds PrefixBounds) []prefixListEntry {
+	type lengths struct {
+		ge, le int
+	}
+
+	groups := make(map[lengths][]Route)
+	for _, route := range routes {
+		ge, le := bounds.bounds(route.Prefix)
+		groups[lengths{ge, le}] = append(groups[lengths{ge, le}], route)
+	}
+
+	var entries []prefixListEntry
+	for l, group := range groups {
+		prefixes := make([]netip.Prefix, 0, len(group))
+		for _, route := range group {
+			prefixes = append(prefixes, route.Prefix)
+		}
+
+		for _, prefix := range cidrset.New(prefixes...).Prefixes() {
+			var sources []Source
+			for _, route := range group {
+				if prefix.Overlaps(route.Prefix) {
+					sources = append(sources, route.Sources...)
+				}
+			}
+
+			entries = append(entries, prefixListEntry{
+				route: Route{Prefix: prefix, Sources: sortSources(sources)},
+				ge:    l.ge,
+				le:    l.le,
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].route.Prefix.Bits() != entries[j].route.Prefix.Bits() {
+			return entries[i].route.Prefix.Bits() < entries[j].route.Prefix.Bits()
+		}
+
+		return routeLess(entries[i].route, entries[j].route)
+	})
+
+	return entries
+}
+
+// BIRD writes BIRD 2 prefix set definitions for IPv4 and IPv6 routes. BIRD has no empty sets, so a set without
+// routes is left out with a comment and filters referring to it fail to load instead of accepting everything.
+func BIRD(w io.Writer, routes Routes, params PrefixListParams) error {
+	name := identifier(defaults.String(params.Name, "NETBLOCKS"))
+
+	var b bytes.Buffer
+
+	b.WriteString(header)
+	writeBIRDSet(&b, name+"_V4", prefixList(routes.IPv4, params.IPv4))
+	writeBIRDSet(&b, name+"_V6", prefixList(routes.IPv6, params.IPv6))
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// writeBIRDSet writes a single BIRD prefix set.
+func writeBIRDSet(b *bytes.Buffer, name string, entries []prefixListEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintf(b, "# %s is not defined: no routes\n", name)
+		return
+	}
+
+	fmt.Fprintf(b, "define %s = [\n", name)
+
+	for i, e := range entries {
+		separator := ","
+		if i == len(entries)-1 {
+			separator = ""
+		}
+
+		pattern := e.route.Prefix.String()
+		if e.ge != e.route.Prefix.Bits() || e.le != e.route.Prefix.Bits() {
+			pattern += fmt.Sprintf("{%d,%d}", e.ge, e.le)
+		}
+
+		fmt.Fprintf(b, "\t%s%s # %s\n", pattern, separator, routeComment(e.route))
+	}
+
+	b.WriteString("];\n")
+}
+
+// FRR writes FRRouting prefix lists for IPv4 and IPv6 routes. An empty list denies everything.
+func FRR(w io.Writer, routes Routes, params PrefixListParams) error {
+	return writeCiscoStyle(w, routes, params, false)
+}
+
+// IOS writes Cisco IOS prefix lists for IPv4 and IPv6 routes. An empty list denies everything.
+func IOS(w io.Writer, routes Routes, params PrefixListParams) error {
+	return writeCiscoStyle(w, routes, params, true)
+}
+
+// writeCiscoStyle writes prefix lists in the syntax shared by FRRouting and Cisco IOS.
+// IOS lists get a description, FRR lists get a comment instead.
+func writeCiscoStyle(w io.Writer, routes Routes, params PrefixListParams, description bool) error {
+	name := identifier(defaults.String(params.Name, "NETBLOCKS"))
+
+	var b bytes.Buffer
+
+	b.WriteString(strings.Replace(header, "#", "!", 1))
+
+	lists := []struct {
+		command string
+		name    string
+		any     string
+		entries []prefixListEntry
+	}{
+		{"ip prefix-list", name + "-V4", "0.0.0.0/0 le 32", prefixList(routes.IPv4, params.IPv4)},
+		{"ipv6 prefix-list", name + "-V6", "::/0 le 128", prefixList(routes.IPv6, params.IPv6)},
+	}
+
+	for _, list := range lists {
+		if description {
+			fmt.Fprintf(&b, "%s %s description Generated by ip-netblocks-go\n", list.command, list.name)
+		}
+
+		if len(list.entries) == 0 {
+			fmt.Fprintf(&b, "%s %s seq 5 deny %s\n", list.command, list.name, list.any)
+			continue
+		}
+
+		for i, e := range list.entries {
+			if !description {
+				fmt.Fprintf(&b, "! %s\n", routeComment(e.route))
+			}
+
+			fmt.Fprintf(&b, "%s %s seq %d permit %s", list.command, list.name, (i+1)*5, e.route.Prefix)
+			switch {
+			case e.ge != e.route.Prefix.Bits():
+				fmt.Fprintf(&b, " ge %d le %d", e.ge, e.le)
+			case e.le != e.route.Prefix.Bits():
+				fmt.Fprintf(&b, " le %d", e.le)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// routeComment returns the sources of the route joined into a single line.
+func routeComment(route Route) string {
+	return sourcesComment(route.Sources)
+}
+
+// sortRoutes sorts routes in address order, wider prefixes first.
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		return routeLess(routes[i], routes[j])
+	})
+}
+
+// routeLess reports whether the route goes before the other one in address order, wider prefixes first.
+func routeLess(a, b Route) bool {
+	if c := a.Prefix.Addr().Compare(b.Prefix.Addr()); c != 0 {
+		return c < 0
+	}
+
+	return a.Prefix.Bits() < b.Prefix.Bits()
+}
+
+// identifier replaces characters not allowed in list names with underscores.
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, s)
+}
@@ -0,0 +1,201 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// testRouteInetnums is the sample list of netblocks returned by an ASN request for testing.
+var testRouteInetnums = []ipnetblocks.Inetnum{
+	{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8", AS: ipnetblocks.AS{ASN: 15169, Route: "8.8.8.0/24"}},
+	{Inetnum: "8.8.0.0 - 8.8.255.255", Netname: "GOGL", AS: ipnetblocks.AS{ASN: 15169, Route: "8.8.0.0/16"}},
+	{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "GOGL-DUP", AS: ipnetblocks.AS{ASN: 15169, Route: "8.8.8.0/24"}},
+	{Inetnum: "2001:4860:: - 2001:4860:ffff:ffff:ffff:ffff:ffff:ffff", Netname: "GOOGLE-IPV6", AS: ipnetblocks.AS{ASN: 15169}},
+}
+
+// TestPrefixLists tests the BIRD, FRR and IOS functions.
+func TestPrefixLists(t *testing.T) {
+	routes, err := CollectRoutes(testRouteInetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		render func(b *bytes.Buffer) error
+		want   string
+	}{
+		{
+			name: "bird exact",
+			render: func(b *bytes.Buffer) error {
+				return BIRD(b, routes, PrefixListParams{Name: "AS15169"})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+define AS15169_V4 = [
+	8.8.0.0/16, # GOGL AS15169
+	8.8.8.0/24 # GOGL-DUP AS15169, LVLT-GOGL-8-8-8 AS15169
+];
+define AS15169_V6 = [
+	2001:4860::/32 # GOOGLE-IPV6 AS15169
+];
+`,
+		},
+		{
+			name: "bird bounds",
+			render: func(b *bytes.Buffer) error {
+				return BIRD(b, routes, PrefixListParams{
+					Name: "AS15169",
+					IPv4: PrefixBounds{LE: 24},
+					IPv6: PrefixBounds{GE: 40, LE: 48},
+				})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+define AS15169_V4 = [
+	8.8.0.0/16{16,24} # GOGL AS15169
+];
+define AS15169_V6 = [
+	2001:4860::/32{40,48} # GOOGLE-IPV6 AS15169
+];
+`,
+		},
+		{
+			name: "frr",
+			render: func(b *bytes.Buffer) error {
+				return FRR(b, routes, PrefixListParams{IPv4: PrefixBounds{LE: 24}, IPv6: PrefixBounds{GE: 48}})
+			},
+			want: `! Generated by ip-netblocks-go. Do not edit.
+! GOGL AS15169
+ip prefix-list NETBLOCKS-V4 seq 5 permit 8.8.0.0/16 le 24
+! GOOGLE-IPV6 AS15169
+ipv6 prefix-list NETBLOCKS-V6 seq 5 permit 2001:4860::/32 ge 48 le 48
+`,
+		},
+		{
+			name: "ios",
+			render: func(b *bytes.Buffer) error {
+				return IOS(b, Routes{IPv4: routes.IPv4}, PrefixListParams{Name: "GOOGLE"})
+			},
+			want: `! Generated by ip-netblocks-go. Do not edit.
+ip prefix-list GOOGLE-V4 description Generated by ip-netblocks-go
+ip prefix-list GOOGLE-V4 seq 5 permit 8.8.0.0/16
+ip prefix-list GOOGLE-V4 seq 10 permit 8.8.8.0/24
+ipv6 prefix-list GOOGLE-V6 description Generated by ip-netblocks-go
+ipv6 prefix-list GOOGLE-V6 seq 5 deny ::/0 le 128
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.render(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	_, err = CollectRoutes([]ipnetblocks.Inetnum{{AS: ipnetblocks.AS{Route: "8.8.8.0"}}})
+	checkErr(t, err, `cannot parse route "8.8.8.0": netip.ParsePrefix("8.8.8.0"): no '/'`)
+}
+
+// TestPrefixListsAggregation tests that the BIRD, FRR and IOS functions aggregate adjacent routes.
+func TestPrefixListsAggregation(t *testing.T) {
+	routes, err := CollectRoutes([]ipnetblocks.Inetnum{
+		{Inetnum: "10.0.0.0 - 10.0.0.127", Netname: "A", AS: ipnetblocks.AS{ASN: 64500, Route: "10.0.0.0/25"}},
+		{Inetnum: "10.0.0.128 - 10.0.0.255", Netname: "B", AS: ipnetblocks.AS{ASN: 64500, Route: "10.0.0.128/25"}},
+		{Inetnum: "10.0.1.0 - 10.0.1.255", Netname: "C", AS: ipnetblocks.AS{ASN: 64500, Route: "10.0.1.0/24"}},
+		{Inetnum: "10.0.3.0 - 10.0.3.255", Netname: "D", AS: ipnetblocks.AS{ASN: 64500, Route: "10.0.3.0/24"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		render func(b *bytes.Buffer) error
+		want   string
+	}{
+		{
+			name: "bird exact",
+			render: func(b *bytes.Buffer) error {
+				return BIRD(b, routes, PrefixListParams{Name: "AS64500"})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+define AS64500_V4 = [
+	10.0.0.0/24{25,25}, # A AS64500, B AS64500
+	10.0.1.0/24, # C AS64500
+	10.0.3.0/24 # D AS64500
+];
+# AS64500_V6 is not defined: no routes
+`,
+		},
+		{
+			name: "bird bounds",
+			render: func(b *bytes.Buffer) error {
+				return BIRD(b, routes, PrefixListParams{Name: "AS64500", IPv4: PrefixBounds{GE: 25, LE: 64}})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+define AS64500_V4 = [
+	10.0.0.0/23{25,32}, # A AS64500, B AS64500, C AS64500
+	10.0.3.0/24{25,32} # D AS64500
+];
+# AS64500_V6 is not defined: no routes
+`,
+		},
+		{
+			name: "frr",
+			render: func(b *bytes.Buffer) error {
+				return FRR(b, routes, PrefixListParams{IPv6: PrefixBounds{GE: 200}})
+			},
+			want: `! Generated by ip-netblocks-go. Do not edit.
+! A AS64500, B AS64500
+ip prefix-list NETBLOCKS-V4 seq 5 permit 10.0.0.0/24 ge 25 le 25
+! C AS64500
+ip prefix-list NETBLOCKS-V4 seq 10 permit 10.0.1.0/24
+! D AS64500
+ip prefix-list NETBLOCKS-V4 seq 15 permit 10.0.3.0/24
+ipv6 prefix-list NETBLOCKS-V6 seq 5 deny ::/0 le 128
+`,
+		},
+		{
+			name: "ios bounds",
+			render: func(b *bytes.Buffer) error {
+				return IOS(b, Routes{IPv4: routes.IPv4}, PrefixListParams{IPv4: PrefixBounds{GE: 40}})
+			},
+			want: `! Generated by ip-netblocks-go. Do not edit.
+ip prefix-list NETBLOCKS-V4 description Generated by ip-netblocks-go
+ip prefix-list NETBLOCKS-V4 seq 5 permit 10.0.0.0/23 ge 32 le 32
+ip prefix-list NETBLOCKS-V4 seq 10 permit 10.0.3.0/24 ge 32 le 32
+ipv6 prefix-list NETBLOCKS-V6 description Generated by ip-netblocks-go
+ipv6 prefix-list NETBLOCKS-V6 seq 5 deny ::/0 le 128
+`,
+		},
+		{
+			name: "bird empty",
+			render: func(b *bytes.Buffer) error {
+				return BIRD(b, Routes{}, PrefixListParams{})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+# NETBLOCKS_V4 is not defined: no routes
+# NETBLOCKS_V6 is not defined: no routes
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.render(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
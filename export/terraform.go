@@ -0,0 +1,229 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// defaultRuleCIDRLimit is the default maximum number of CIDRs per rule, which is the AWS limit
+// of inbound or outbound rules per security group.
+const defaultRuleCIDRLimit = 60
+
+// TerraformParams is used to render Terraform and AWS exports. None of parameters are mandatory.
+type TerraformParams struct {
+	// Name is the base name of the generated lists, suffixed with the family and the chunk number.
+	// Default: "netblocks".
+	Name string
+
+	// CIDRLimit is the maximum number of CIDRs in a single list or rule. Default: 60.
+	CIDRLimit int
+}
+
+// chunk is a named part of a CIDR list which fits into the limit.
+type chunk struct {
+	name     string
+	prefixes []netip.Prefix
+}
+
+// chunks splits IPv4 and IPv6 prefixes of the blocks into named lists which fit into the limit.
+// The first chunk of a family is named after the family, the following ones get a number suffix.
+func (p TerraformParams) chunks(blocks Blocks) (ipv4, ipv6 []chunk) {
+	name := identifier(defaults.String(p.Name, "netblocks"))
+
+	limit := p.CIDRLimit
+	if limit < 1 {
+		limit = defaultRuleCIDRLimit
+	}
+
+	split := func(family string, prefixes []netip.Prefix) []chunk {
+		var chunks []chunk
+		for i := 0; i < len(prefixes); i += limit {
+			end := i + limit
+			if end > len(prefixes) {
+				end = len(prefixes)
+			}
+
+			chunkName := name + "_" + family
+			if i > 0 {
+				chunkName += "_" + strconv.Itoa(i/limit+1)
+			}
+
+			chunks = append(chunks, chunk{name: chunkName, prefixes: prefixes[i:end]})
+		}
+
+		return chunks
+	}
+
+	return split("ipv4", Prefixes(blocks.IPv4)), split("ipv6", Prefixes(blocks.IPv6))
+}
+
+// TerraformLocals writes the Terraform locals block with lists of IPv4 and IPv6 CIDRs.
+// Lists longer than the limit are split into several ones.
+func TerraformLocals(w io.Writer, blocks Blocks, params TerraformParams) error {
+	ipv4, ipv6 := params.chunks(blocks)
+
+	var b bytes.Buffer
+
+	b.WriteString(header)
+	b.WriteString("locals {\n")
+
+	for _, c := range append(ipv4, ipv6...) {
+		fmt.Fprintf(&b, "  %s = [\n", c.name)
+		for _, prefix := range c.prefixes {
+			fmt.Fprintf(&b, "    %q,\n", prefix)
+		}
+		b.WriteString("  ]\n")
+	}
+
+	b.WriteString("}\n")
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// TerraformVars writes the .tfvars.json file with lists of IPv4 and IPv6 CIDRs.
+// Lists longer than the limit are split into several ones.
+func TerraformVars(w io.Writer, blocks Blocks, params TerraformParams) error {
+	ipv4, ipv6 := params.chunks(blocks)
+
+	vars := make(map[string][]string)
+	for _, c := range append(ipv4, ipv6...) {
+		vars[c.name] = prefixStrings(c.prefixes)
+	}
+
+	return writeJSON(w, vars)
+}
+
+// SecurityGroupRule is the AWS security group rule in the format accepted by "aws ec2
+// authorize-security-group-ingress --ip-permissions" and "authorize-security-group-egress --ip-permissions".
+type SecurityGroupRule struct {
+	IPProtocol string            `json:"IpProtocol"`
+	FromPort   *int              `json:"FromPort,omitempty"`
+	ToPort     *int              `json:"ToPort,omitempty"`
+	IPRanges   []SecurityGroupIP `json:"IpRanges,omitempty"`
+	IPv6Ranges []SecurityGroupIP `json:"Ipv6Ranges,omitempty"`
+}
+
+// SecurityGroupIP is the CIDR of the AWS security group rule.
+type SecurityGroupIP struct {
+	CIDRIP      string `json:"CidrIp,omitempty"`
+	CIDRIPv6    string `json:"CidrIpv6,omitempty"`
+	Description string `json:"Description,omitempty"`
+}
+
+// SecurityGroupParams is used to render AWS security group rules. None of parameters are mandatory.
+type SecurityGroupParams struct {
+	// Protocol is the IP protocol name or number. Default: "-1", i.e. all protocols.
+	Protocol string
+
+	// FromPort is the start of the port range. Omitted when nil.
+	FromPort *int
+
+	// ToPort is the end of the port range. Omitted when nil.
+	ToPort *int
+
+	// CIDRLimit is the maximum number of CIDRs in a single rule. Default: 60.
+	CIDRLimit int
+}
+
+// SecurityGroupRules writes the JSON array of AWS security group rules allowing the blocks.
+// Each CIDR is described with its sources, and rules longer than the limit are split into several ones.
+func SecurityGroupRules(w io.Writer, blocks Blocks, params SecurityGroupParams) error {
+	limit := params.CIDRLimit
+	if limit < 1 {
+		limit = defaultRuleCIDRLimit
+	}
+
+	protocol := defaults.String(params.Protocol, "-1")
+
+	var ranges []SecurityGroupIP
+	for _, list := range [][]Block{blocks.IPv4, blocks.IPv6} {
+		for _, block := range list {
+			for _, prefix := range block.Prefixes {
+				ip := SecurityGroupIP{Description: securityGroupDescription(block.Comment())}
+				if prefix.Addr().Is4() {
+					ip.CIDRIP = prefix.String()
+				} else {
+					ip.CIDRIPv6 = prefix.String()
+				}
+				ranges = append(ranges, ip)
+			}
+		}
+	}
+
+	rules := []SecurityGroupRule{}
+	for i := 0; i < len(ranges); i += limit {
+		end := i + limit
+		if end > len(ranges) {
+			end = len(ranges)
+		}
+
+		rule := SecurityGroupRule{IPProtocol: protocol, FromPort: params.FromPort, ToPort: params.ToPort}
+		for _, ip := range ranges[i:end] {
+			if ip.CIDRIP != "" {
+				rule.IPRanges = append(rule.IPRanges, ip)
+			} else {
+				rule.IPv6Ranges = append(rule.IPv6Ranges, ip)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return writeJSON(w, rules)
+}
+
+// securityGroupDescriptionChars is the list of characters other than ASCII letters and digits AWS accepts
+// in security group rule descriptions.
+const securityGroupDescriptionChars = " ._-:/()#,@[]+=&;{}!$*"
+
+// securityGroupDescription replaces characters AWS doesn't accept in descriptions with underscores and trims
+// the description to the AWS limit of 255 characters.
+func securityGroupDescription(s string) string {
+	const maxLen = 255
+
+	s = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune(securityGroupDescriptionChars, r) {
+			return r
+		}
+
+		return '_'
+	}, s)
+
+	if len(s) <= maxLen {
+		return s
+	}
+
+	return s[:maxLen-len("...")] + "..."
+}
+
+// prefixStrings returns string representations of the prefixes.
+func prefixStrings(prefixes []netip.Prefix) []string {
+	list := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		list = append(list, prefix.String())
+	}
+
+	return list
+}
+
+// writeJSON writes the value as indented JSON. Map keys are sorted, so the output is stable.
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
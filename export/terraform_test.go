@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestTerraform tests the TerraformLocals, TerraformVars and SecurityGroupRules functions.
+func TestTerraform(t *testing.T) {
+	blocks, err := Aggregate(testInetnums)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := 443
+
+	tests := []struct {
+		name   string
+		render func(b *bytes.Buffer) error
+		want   string
+	}{
+		{
+			name: "locals",
+			render: func(b *bytes.Buffer) error {
+				return TerraformLocals(b, blocks, TerraformParams{Name: "google", CIDRLimit: 2})
+			},
+			want: `# Generated by ip-netblocks-go. Do not edit.
+locals {
+  google_ipv4 = [
+    "8.8.4.0/24",
+    "8.8.8.0/24",
+  ]
+  google_ipv4_2 = [
+    "8.8.9.0/25",
+  ]
+  google_ipv6 = [
+    "2001:4860::/32",
+  ]
+}
+`,
+		},
+		{
+			name: "tfvars",
+			render: func(b *bytes.Buffer) error {
+				return TerraformVars(b, blocks, TerraformParams{})
+			},
+			want: `{
+  "netblocks_ipv4": [
+    "8.8.4.0/24",
+    "8.8.8.0/24",
+    "8.8.9.0/25"
+  ],
+  "netblocks_ipv6": [
+    "2001:4860::/32"
+  ]
+}
+`,
+		},
+		{
+			name: "security group rules",
+			render: func(b *bytes.Buffer) error {
+				return SecurityGroupRules(b, blocks, SecurityGroupParams{
+					Protocol:  "tcp",
+					FromPort:  &port,
+					ToPort:    &port,
+					CIDRLimit: 3,
+				})
+			},
+			want: `[
+  {
+    "IpProtocol": "tcp",
+    "FromPort": 443,
+    "ToPort": 443,
+    "IpRanges": [
+      {
+        "CidrIp": "8.8.4.0/24",
+        "Description": "GOGL AS15169"
+      },
+      {
+        "CidrIp": "8.8.8.0/24",
+        "Description": "EXAMPLE, LVLT-GOGL-8-8-8 AS15169"
+      },
+      {
+        "CidrIp": "8.8.9.0/25",
+        "Description": "EXAMPLE, LVLT-GOGL-8-8-8 AS15169"
+      }
+    ]
+  },
+  {
+    "IpProtocol": "tcp",
+    "FromPort": 443,
+    "ToPort": 443,
+    "Ipv6Ranges": [
+      {
+        "CidrIpv6": "2001:4860::/32",
+        "Description": "GOOGLE-IPV6 AS15169"
+      }
+    ]
+  }
+]
+`,
+		},
+		{
+			name: "empty security group rules",
+			render: func(b *bytes.Buffer) error {
+				return SecurityGroupRules(b, Blocks{}, SecurityGroupParams{})
+			},
+			want: "[]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.render(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			// Re-rendering must produce byte-identical output.
+			var again bytes.Buffer
+			if err := tt.render(&again); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(b.Bytes(), again.Bytes()) {
+				t.Errorf("output is not stable")
+			}
+		})
+	}
+}
+
+// TestSecurityGroupDescription tests that security group rule descriptions are accepted by AWS.
+func TestSecurityGroupDescription(t *testing.T) {
+	valid := regexp.MustCompile(`^[a-zA-Z0-9 ._\-:/()#,@\[\]+=&;{}!$*]{0,255}$`)
+
+	name := strings.Repeat("Société Générale \"Ω\" <b>|~ ", 20)
+
+	blocks := Blocks{IPv4: []Block{{
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+		Sources:  []Source{{Netname: name, ASN: 64500}},
+	}}}
+
+	var b bytes.Buffer
+	if err := SecurityGroupRules(&b, blocks, SecurityGroupParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rules []SecurityGroupRule
+	if err := json.Unmarshal(b.Bytes(), &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rules[0].IPRanges[0].Description
+	if !valid.MatchString(got) || !strings.HasSuffix(got, "...") || !strings.HasPrefix(got, "Soci_t_ G_n_rale") {
+		t.Errorf("Description = %q (%d bytes)", got, len(got))
+	}
+
+	for n := 250; n < 260; n++ {
+		s := "a" + strings.Repeat("é", n)
+		if got := securityGroupDescription(s); !valid.MatchString(got) {
+			t.Errorf("securityGroupDescription(%d runes) = %q (%d bytes)", n+1, got, len(got))
+		}
+	}
+
+	if got := securityGroupDescription("GOGL AS15169, [x]"); got != "GOGL AS15169, [x]" {
+		t.Errorf("securityGroupDescription() = %q", got)
+	}
+}
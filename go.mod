@@ -1,3 +1,3 @@
 module github.com/whois-api-llc/ip-netblocks-go
 
-go 1.17
+go 1.18
@@ -0,0 +1,248 @@
+package ipnetblocks
+
+import (
+	"sort"
+)
+
+// SelectionPolicy defines how Hierarchy chooses between registry-sourced and BGP-derived netblocks.
+type SelectionPolicy int
+
+const (
+	// PreferRegistry selects among registry-sourced netblocks, and falls back to BGP-derived ones only when
+	// there are no registry-sourced netblocks at all.
+	PreferRegistry SelectionPolicy = iota
+
+	// PreferBGP selects among BGP-derived netblocks, and falls back to registry-sourced ones only when
+	// there are no BGP-derived netblocks at all.
+	PreferBGP
+
+	// PreferNarrowest selects among all netblocks. A registry-sourced netblock wins over a BGP-derived one with
+	// the same range.
+	PreferNarrowest
+)
+
+// Node is a netblock in the hierarchy.
+type Node struct {
+	// Inetnum is the netblock.
+	Inetnum Inetnum
+
+	// Range is the parsed range of the netblock.
+	Range Range
+
+	// Parent is the narrowest netblock containing this one, or nil for a root.
+	Parent *Node
+
+	// Origin is the netblock referenced by the Parent field of a BGP-derived netblock, or nil when it's
+	// not among the arranged netblocks.
+	Origin *Node
+
+	// Children is the list of netblocks directly contained in this one, in address order.
+	Children []*Node
+}
+
+// IsBGP reports whether the netblock was obtained from BGP routing tables.
+func (n *Node) IsBGP() bool {
+	return n.Inetnum.Parent != ""
+}
+
+// Hierarchy is the tree of netblocks arranged by containment.
+type Hierarchy struct {
+	// Roots is the list of netblocks not contained in any other one, in address order.
+	Roots []*Node
+
+	policy SelectionPolicy
+	nodes  []*Node
+}
+
+// NewHierarchy arranges the netblocks, e.g. returned by GetByIP, into the tree by containment. Among several
+// netblocks with the same range, a BGP-derived netblock is placed under the one referenced by its Parent field.
+// Netblocks with unparsable ranges are ignored.
+func NewHierarchy(inetnums []Inetnum, policy SelectionPolicy) *Hierarchy {
+	h := &Hierarchy{policy: policy}
+
+	for _, inetnum := range inetnums {
+		r, err := inetnum.Range()
+		if err != nil {
+			continue
+		}
+
+		h.nodes = append(h.nodes, &Node{Inetnum: inetnum, Range: r})
+	}
+
+	// Wider netblocks go first, so that parents are placed before their children. A registry-sourced netblock
+	// goes before a BGP-derived one with the same range to become its parent.
+	sort.SliceStable(h.nodes, func(i, j int) bool {
+		a, b := h.nodes[i], h.nodes[j]
+		if c := a.Range.First.Compare(b.Range.First); c != 0 {
+			return c < 0
+		}
+
+		if c := a.Range.Last.Compare(b.Range.Last); c != 0 {
+			return c > 0
+		}
+
+		return !a.IsBGP() && b.IsBGP()
+	})
+
+	for i, node := range h.nodes {
+		node.Origin = h.origin(node)
+		node.Parent = h.parent(node, h.nodes[:i])
+
+		if node.Parent == nil {
+			h.Roots = append(h.Roots, node)
+		} else {
+			node.Parent.Children = append(node.Parent.Children, node)
+		}
+	}
+
+	return h
+}
+
+// origin returns the netblock referenced by the Parent field of the node.
+func (h *Hierarchy) origin(node *Node) *Node {
+	if !node.IsBGP() {
+		return nil
+	}
+
+	ref, err := ParseRange(node.Inetnum.Parent)
+	if err != nil {
+		return nil
+	}
+
+	for _, candidate := range h.nodes {
+		if candidate != node && !candidate.IsBGP() && candidate.Range == ref {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// parent returns the narrowest of the placed netblocks containing the node. The origin of the node wins over
+// other netblocks with the same range.
+func (h *Hierarchy) parent(node *Node, placed []*Node) *Node {
+	var narrowest *Node
+
+	for _, candidate := range placed {
+		if !contains(candidate.Range, node.Range) {
+			continue
+		}
+
+		if narrowest != nil && narrowest.Range == candidate.Range && narrowest == node.Origin {
+			continue
+		}
+
+		narrowest = candidate
+	}
+
+	return narrowest
+}
+
+// candidates returns the nodes the policy selects among.
+func (h *Hierarchy) candidates() []*Node {
+	if h.policy == PreferNarrowest {
+		return h.nodes
+	}
+
+	var registry, bgp []*Node
+	for _, node := range h.nodes {
+		if node.IsBGP() {
+			bgp = append(bgp, node)
+		} else {
+			registry = append(registry, node)
+		}
+	}
+
+	preferred, fallback := registry, bgp
+	if h.policy == PreferBGP {
+		preferred, fallback = bgp, registry
+	}
+
+	if len(preferred) > 0 {
+		return preferred
+	}
+
+	return fallback
+}
+
+// mostSpecific returns the narrowest of the selected nodes. Among nodes with the same range a registry-sourced
+// one wins, then the deepest one.
+func (h *Hierarchy) mostSpecific() *Node {
+	var found *Node
+
+	for _, node := range h.candidates() {
+		switch {
+		case found == nil:
+			found = node
+		case node.Range != found.Range:
+			if contains(found.Range, node.Range) {
+				found = node
+			}
+		case found.IsBGP() != node.IsBGP():
+			if !node.IsBGP() {
+				found = node
+			}
+		case node.depth() > found.depth():
+			found = node
+		}
+	}
+
+	return found
+}
+
+// MostSpecific returns the narrowest netblock selected by the policy, or nil if there are no netblocks.
+func (h *Hierarchy) MostSpecific() *Inetnum {
+	node := h.mostSpecific()
+	if node == nil {
+		return nil
+	}
+
+	return &node.Inetnum
+}
+
+// LeastSpecific returns the widest netblock of the chain, or nil if there are no netblocks.
+func (h *Hierarchy) LeastSpecific() *Inetnum {
+	chain := h.Chain()
+	if len(chain) == 0 {
+		return nil
+	}
+
+	return &chain[0]
+}
+
+// Chain returns the netblocks selected by the policy which contain the most specific one, from the widest
+// to the most specific one.
+func (h *Hierarchy) Chain() []Inetnum {
+	selected := make(map[*Node]bool)
+	for _, node := range h.candidates() {
+		selected[node] = true
+	}
+
+	var chain []Inetnum
+	for node := h.mostSpecific(); node != nil; node = node.Parent {
+		if selected[node] {
+			chain = append(chain, node.Inetnum)
+		}
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return chain
+}
+
+// depth returns the number of ancestors of the node.
+func (n *Node) depth() int {
+	depth := 0
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		depth++
+	}
+
+	return depth
+}
+
+// contains reports whether the range a contains the range b.
+func contains(a, b Range) bool {
+	return a.Contains(b.First) && a.Contains(b.Last)
+}
@@ -0,0 +1,103 @@
+package ipnetblocks
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestHierarchy tests the Hierarchy functions.
+func TestHierarchy(t *testing.T) {
+	inetnums := []Inetnum{
+		{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8", Source: "ARIN"},
+		{Inetnum: "8.0.0.0 - 8.127.255.255", Netname: "LVLT-ORG-8-8", Source: "ARIN"},
+		{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "GOOGLE-ROUTE", Parent: "8.8.8.0 - 8.8.8.255", Source: "RADB"},
+		{Inetnum: "8.8.8.0 - 8.8.8.127", Netname: "GOOGLE-BGP", Parent: "8.0.0.0 - 8.127.255.255", Source: "RADB"},
+		{Inetnum: "invalid", Netname: "INVALID"},
+	}
+
+	netnames := func(inetnums []Inetnum) []string {
+		var names []string
+		for _, inetnum := range inetnums {
+			names = append(names, inetnum.Netname)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name          string
+		policy        SelectionPolicy
+		mostSpecific  string
+		leastSpecific string
+		chain         []string
+	}{
+		{
+			name:          "prefer registry",
+			policy:        PreferRegistry,
+			mostSpecific:  "LVLT-GOGL-8-8-8",
+			leastSpecific: "LVLT-ORG-8-8",
+			chain:         []string{"LVLT-ORG-8-8", "LVLT-GOGL-8-8-8"},
+		},
+		{
+			name:          "prefer BGP",
+			policy:        PreferBGP,
+			mostSpecific:  "GOOGLE-BGP",
+			leastSpecific: "GOOGLE-ROUTE",
+			chain:         []string{"GOOGLE-ROUTE", "GOOGLE-BGP"},
+		},
+		{
+			name:          "prefer narrowest",
+			policy:        PreferNarrowest,
+			mostSpecific:  "GOOGLE-BGP",
+			leastSpecific: "LVLT-ORG-8-8",
+			chain:         []string{"LVLT-ORG-8-8", "LVLT-GOGL-8-8-8", "GOOGLE-ROUTE", "GOOGLE-BGP"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHierarchy(inetnums, tt.policy)
+
+			if got := h.MostSpecific(); got == nil || got.Netname != tt.mostSpecific {
+				t.Errorf("Hierarchy.MostSpecific() = %v, want %v", got, tt.mostSpecific)
+			}
+
+			if got := h.LeastSpecific(); got == nil || got.Netname != tt.leastSpecific {
+				t.Errorf("Hierarchy.LeastSpecific() = %v, want %v", got, tt.leastSpecific)
+			}
+
+			if got := netnames(h.Chain()); !reflect.DeepEqual(got, tt.chain) {
+				t.Errorf("Hierarchy.Chain() = %v, want %v", got, tt.chain)
+			}
+		})
+	}
+
+	h := NewHierarchy(inetnums, PreferRegistry)
+	if len(h.Roots) != 1 || len(h.Roots[0].Children) != 1 {
+		t.Fatalf("NewHierarchy() built unexpected tree: %v", h.Roots)
+	}
+
+	gogl := h.Roots[0].Children[0]
+	if len(gogl.Children) != 1 || gogl.Children[0].Inetnum.Netname != "GOOGLE-ROUTE" ||
+		gogl.Children[0].Origin != gogl {
+		t.Fatalf("BGP-derived netblock is not placed under its origin: %v", gogl.Children)
+	}
+
+	if bgp := gogl.Children[0].Children[0]; bgp.Inetnum.Netname != "GOOGLE-BGP" || bgp.Origin != h.Roots[0] {
+		t.Errorf("BGP-derived netblock has unexpected origin: %v", bgp.Origin)
+	}
+
+	if got := NewHierarchy(nil, PreferRegistry).MostSpecific(); got != nil {
+		t.Errorf("Hierarchy.MostSpecific() = %v, want nil", got)
+	}
+}
+
+// TestHierarchySameRange tests that a registry-sourced netblock wins over a BGP-derived one with the same range.
+func TestHierarchySameRange(t *testing.T) {
+	h := NewHierarchy([]Inetnum{
+		{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "GOOGLE-ROUTE", Parent: "8.8.8.0 - 8.8.8.255", Source: "RADB"},
+		{Inetnum: "8.8.8.0 - 8.8.8.255", Netname: "LVLT-GOGL-8-8-8", Source: "ARIN"},
+	}, PreferNarrowest)
+
+	if got := h.MostSpecific(); got == nil || got.Netname != "LVLT-GOGL-8-8-8" {
+		t.Errorf("Hierarchy.MostSpecific() = %v, want LVLT-GOGL-8-8-8", got)
+	}
+}
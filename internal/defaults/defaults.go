@@ -0,0 +1,11 @@
+// Package defaults fills in default values of optional parameters.
+package defaults
+
+// String returns the value or the default one if the value is empty.
+func String(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
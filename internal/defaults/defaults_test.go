@@ -0,0 +1,23 @@
+package defaults
+
+import "testing"
+
+// TestString tests the String function.
+func TestString(t *testing.T) {
+	tests := []struct {
+		value        string
+		defaultValue string
+		want         string
+	}{
+		{"", "netblocks", "netblocks"},
+		{"blocklist", "netblocks", "blocklist"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := String(tt.value, tt.defaultValue); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 )
 
@@ -39,6 +40,21 @@ type IPNetblocks interface {
 	GetRawByOrg(ctx context.Context, org string, opts ...Option) (*Response, error)
 }
 
+// RangeResolver is an interface for lookups which combine several IP Netblocks API requests.
+type RangeResolver interface {
+	// GetByRange returns merged IP Netblocks API responses for the minimal set of CIDRs covering the IP range.
+	GetByRange(ctx context.Context, ipRange string, opts ...Option) (*IPNetblocksResponse, error)
+
+	// GetByHost resolves the host name and returns parsed IP Netblocks API responses keyed by the resolved addresses.
+	GetByHost(ctx context.Context, host string, opts ...Option) (map[string]*IPNetblocksResponse, error)
+}
+
+// AbuseResolver is an interface for abuse contact lookups.
+type AbuseResolver interface {
+	// AbuseContact returns the abuse contact of the IP address resolved from the netblocks returned by GetByIP.
+	AbuseContact(ctx context.Context, ip net.IP, opts ...Option) (*Abuse, error)
+}
+
 // Response is the http.Response wrapper with Body saved as a byte slice.
 type Response struct {
 	*http.Response
@@ -53,7 +69,11 @@ type ipNetblocksServiceOp struct {
 	baseURL *url.URL
 }
 
-var _ IPNetblocks = &ipNetblocksServiceOp{}
+var (
+	_ IPNetblocks   = &ipNetblocksServiceOp{}
+	_ RangeResolver = &ipNetblocksServiceOp{}
+	_ AbuseResolver = &ipNetblocksServiceOp{}
+)
 
 // newRequest creates the API request with default parameters and the specified apiKey.
 func (service ipNetblocksServiceOp) newRequest() (*http.Request, error) {
@@ -157,6 +177,27 @@ func (service ipNetblocksServiceOp) GetByIP(
 		return nil, nil, &ArgError{"ip", "can not be empty"}
 	}
 
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return nil, nil, &ArgError{ipString, "is invalid IP address"}
+	}
+
+	// net.IP stores IPv4 addresses in the IPv4-mapped form, so unmapping them is not reported.
+	addr, normalizations, err := service.client.input.NormalizeIP(addr.Unmap())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ipString = addr.String()
+
+	ipNetblocksResponse, err = service.specialPurpose(ipString, netip.PrefixFrom(addr, addr.BitLen()))
+	if ipNetblocksResponse != nil || err != nil {
+		if ipNetblocksResponse != nil {
+			ipNetblocksResponse.Normalizations = normalizations
+		}
+		return ipNetblocksResponse, nil, err
+	}
+
 	optsJSON := make([]Option, 0, len(opts)+1)
 	optsJSON = append(optsJSON, opts...)
 	optsJSON = append(optsJSON, OptionOutputFormat("JSON"))
@@ -178,6 +219,8 @@ func (service ipNetblocksServiceOp) GetByIP(
 		}
 	}
 
+	ipNetblocksResp.Normalizations = normalizations
+
 	return &ipNetblocksResp.IPNetblocksResponse, resp, nil
 }
 
@@ -192,13 +235,26 @@ func (service ipNetblocksServiceOp) GetByCIDR(
 		return nil, nil, &ArgError{"ip", "can not be empty"}
 	}
 
-	maskSize, _ := ip.Mask.Size()
+	prefix, normalizations, err := service.client.input.normalizeIPNet(ip)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ipNetblocksResponse, err = service.specialPurpose(prefix.String(), prefix)
+	if ipNetblocksResponse != nil || err != nil {
+		if ipNetblocksResponse != nil {
+			ipNetblocksResponse.Normalizations = normalizations
+		}
+		return ipNetblocksResponse, nil, err
+	}
+
+	ipString, maskString := prefixQuery(prefix)
 
 	optsJSON := make([]Option, 0, len(opts)+1)
 	optsJSON = append(optsJSON, opts...)
 	optsJSON = append(optsJSON, OptionOutputFormat("JSON"))
 
-	resp, err = service.request(ctx, ipString, fmt.Sprintf("%d", maskSize), "", "", optsJSON...)
+	resp, err = service.request(ctx, ipString, maskString, "", "", optsJSON...)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -215,6 +271,8 @@ func (service ipNetblocksServiceOp) GetByCIDR(
 		}
 	}
 
+	ipNetblocksResp.Normalizations = normalizations
+
 	return &ipNetblocksResp.IPNetblocksResponse, resp, nil
 }
 
@@ -286,6 +344,111 @@ func (service ipNetblocksServiceOp) GetByOrg(
 	return &ipNetblocksResp.IPNetblocksResponse, resp, nil
 }
 
+// GetByRange returns merged IP Netblocks API responses for the minimal set of CIDRs covering the IP range.
+// The range is expected in the "first - last" format used by the Inetnum field. Every CIDR is paginated
+// until the last page, and netblocks returned for several CIDRs are included only once.
+func (service ipNetblocksServiceOp) GetByRange(
+	ctx context.Context,
+	ipRange string,
+	opts ...Option,
+) (ipNetblocksResponse *IPNetblocksResponse, err error) {
+	if ipRange == "" {
+		return nil, &ArgError{"ipRange", "can not be empty"}
+	}
+
+	r, err := ParseRange(ipRange)
+	if err != nil {
+		return nil, err
+	}
+
+	ipNetblocksResponse = &IPNetblocksResponse{
+		Search: r.String(),
+	}
+
+	seen := make(map[string]bool)
+
+	for _, prefix := range r.Prefixes() {
+		var from *string
+
+		for {
+			pageOpts := make([]Option, 0, len(opts)+1)
+			pageOpts = append(pageOpts, opts...)
+			pageOpts = append(pageOpts, optionPage(from))
+
+			resp, _, err := service.GetByCIDR(ctx, prefixIPNet(prefix), pageOpts...)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, inetnum := range resp.Result.Inetnums {
+				key := inetnum.Key()
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				ipNetblocksResponse.Result.Inetnums = append(ipNetblocksResponse.Result.Inetnums, inetnum)
+			}
+
+			if from = resp.Result.Next; from == nil {
+				break
+			}
+		}
+	}
+
+	ipNetblocksResponse.Result.Count = len(ipNetblocksResponse.Result.Inetnums)
+
+	return ipNetblocksResponse, nil
+}
+
+// optionPage sets the "from" query parameter to the page, or removes it for the first page, so that the caller's
+// OptionFrom doesn't skip results of every CIDR.
+func optionPage(from *string) Option {
+	return func(v url.Values) {
+		if from == nil {
+			v.Del("from")
+			return
+		}
+
+		OptionFrom(from)(v)
+	}
+}
+
+// GetByHost resolves the host name and returns parsed IP Netblocks API responses keyed by the resolved addresses.
+// Both IPv4 and IPv6 addresses are looked up, each of them once.
+func (service ipNetblocksServiceOp) GetByHost(
+	ctx context.Context,
+	host string,
+	opts ...Option,
+) (ipNetblocksResponses map[string]*IPNetblocksResponse, err error) {
+	if host == "" {
+		return nil, &ArgError{"host", "can not be empty"}
+	}
+
+	addrs, err := service.client.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve host: %w", err)
+	}
+
+	ipNetblocksResponses = make(map[string]*IPNetblocksResponse, len(addrs))
+
+	for _, addr := range addrs {
+		ipString := addr.IP.String()
+		if _, ok := ipNetblocksResponses[ipString]; ok {
+			continue
+		}
+
+		ipNetblocksResp, _, err := service.GetByIP(ctx, addr.IP, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		ipNetblocksResponses[ipString] = ipNetblocksResp
+	}
+
+	return ipNetblocksResponses, nil
+}
+
 // GetRawByIP returns raw IP Netblocks API response by IP address as Response struct with Body saved
 // as a byte slice.
 func (service ipNetblocksServiceOp) GetRawByIP(
@@ -311,6 +474,7 @@ func (service ipNetblocksServiceOp) GetRawByIP(
 }
 
 // GetRawByCIDR returns raw IP Netblocks API response by CIDR as Response struct with Body saved as a byte slice.
+// The CIDR is normalized as in GetByCIDR.
 func (service ipNetblocksServiceOp) GetRawByCIDR(
 	ctx context.Context,
 	ip net.IPNet,
@@ -321,9 +485,15 @@ func (service ipNetblocksServiceOp) GetRawByCIDR(
 		return nil, &ArgError{"ip", "can not be empty"}
 	}
 
-	maskSize, _ := ip.Mask.Size()
+	// A non-canonical mask has no size, so it's normalized like in GetByCIDR instead of being sent as /0.
+	prefix, _, err := service.client.input.normalizeIPNet(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	ipString, mask := prefixQuery(prefix)
 
-	resp, err = service.request(ctx, ipString, fmt.Sprintf("%d", maskSize), "", "", opts...)
+	resp, err = service.request(ctx, ipString, mask, "", "", opts...)
 	if err != nil {
 		return resp, err
 	}
@@ -0,0 +1,111 @@
+// Package compat_test checks databases written by the mmdb package with an independent MaxMind DB reader.
+// It's a separate module, so that the library keeps no dependencies.
+package compat_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/mmdb"
+)
+
+// record is the decoded record of a netblock.
+type record struct {
+	ASN     uint32 `maxminddb:"autonomous_system_number"`
+	ASName  string `maxminddb:"autonomous_system_organization"`
+	Org     string `maxminddb:"organization"`
+	Netname string `maxminddb:"netname"`
+	Abuse   string `maxminddb:"abuse_email"`
+	Source  string `maxminddb:"source"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+}
+
+// TestMaxMindReader tests that databases written with every record size are read by maxminddb-golang.
+func TestMaxMindReader(t *testing.T) {
+	inetnums := []ipnetblocks.Inetnum{
+		{
+			Inetnum:      "10.0.0.1 - 10.0.0.254",
+			Netname:      "NA",
+			Country:      "DE",
+			Source:       "RIPE",
+			Org:          ipnetblocks.Organization{Name: "OrgA"},
+			AbuseContact: []ipnetblocks.Contact{{Email: "abuse@example.net"}},
+		},
+		{Inetnum: "10.0.0.0 - 10.0.0.127", Netname: "NB", AS: ipnetblocks.AS{ASN: 64500, Name: "EXAMPLE-AS"}},
+		{Inetnum: "2001:db8:: - 2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", Netname: "DOC-NET", Country: "US"},
+	}
+
+	tests := []struct {
+		ip      string
+		network string
+		netname string
+		org     string
+		asn     uint32
+		country string
+	}{
+		{"10.0.0.0", "10.0.0.0/32", "NB", "", 64500, ""},
+		{"10.0.0.5", "10.0.0.4/30", "NB", "OrgA", 64500, "DE"},
+		{"10.0.0.200", "10.0.0.192/27", "NA", "OrgA", 0, "DE"},
+		{"::ffff:10.0.0.200", "10.0.0.192/27", "NA", "OrgA", 0, "DE"},
+		{"2001:db8::1", "2001:db8::/32", "DOC-NET", "", 0, "US"},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		var b bytes.Buffer
+		err := mmdb.Write(&b, inetnums, mmdb.WriterParams{
+			RecordSize:  recordSize,
+			Description: "Test database",
+			BuildTime:   time.Unix(1650000000, 0),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reader, err := maxminddb.FromBytes(b.Bytes())
+		if err != nil {
+			t.Fatalf("FromBytes(RecordSize: %d) error = %v", recordSize, err)
+		}
+
+		if err = reader.Verify(); err != nil {
+			t.Errorf("Verify(RecordSize: %d) error = %v", recordSize, err)
+		}
+
+		meta := reader.Metadata
+		if meta.RecordSize != uint(recordSize) || meta.IPVersion != 6 || meta.DatabaseType != "IP-Netblocks" ||
+			meta.BuildEpoch != 1650000000 || meta.Description["en"] != "Test database" {
+			t.Errorf("Metadata = %+v", meta)
+		}
+
+		for _, tt := range tests {
+			var got record
+			network, ok, err := reader.LookupNetwork(net.ParseIP(tt.ip), &got)
+			if err != nil || !ok {
+				t.Errorf("LookupNetwork(%s) = %v, %v", tt.ip, ok, err)
+				continue
+			}
+
+			if network.String() != tt.network || got.Netname != tt.netname || got.Org != tt.org ||
+				got.ASN != tt.asn || got.Country.ISOCode != tt.country {
+				t.Errorf("LookupNetwork(%s) = %v, %+v", tt.ip, network, got)
+			}
+		}
+
+		var got record
+		if _, ok, err := reader.LookupNetwork(net.ParseIP("10.0.0.5"), &got); err != nil || !ok ||
+			got.ASName != "EXAMPLE-AS" || got.Abuse != "abuse@example.net" || got.Source != "RIPE" ||
+			got.Country.Names["en"] != "Germany" {
+			t.Errorf("LookupNetwork(10.0.0.5) = %+v, %v, %v", got, ok, err)
+		}
+
+		if _, ok, err := reader.LookupNetwork(net.ParseIP("10.0.0.255"), &got); err != nil || ok {
+			t.Errorf("LookupNetwork(10.0.0.255) = %v, %v, want not found", ok, err)
+		}
+	}
+}
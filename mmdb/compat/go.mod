@@ -0,0 +1,12 @@
+module github.com/whois-api-llc/ip-netblocks-go/mmdb/compat
+
+go 1.18
+
+require (
+	github.com/oschwald/maxminddb-golang v1.10.0
+	github.com/whois-api-llc/ip-netblocks-go v0.0.0
+)
+
+require golang.org/x/sys v0.0.0-20220804214406-8e32c043e418 // indirect
+
+replace github.com/whois-api-llc/ip-netblocks-go => ../..
@@ -0,0 +1,348 @@
+package mmdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Data types of the MaxMind DB data section.
+const (
+	typeExtended = 0
+	typePointer  = 1
+	typeString   = 2
+	typeDouble   = 3
+	typeBytes    = 4
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeInt32    = 8
+	typeUint64   = 9
+	typeUint128  = 10
+	typeArray    = 11
+	typeBool     = 14
+	typeFloat    = 15
+)
+
+// encoder writes values in the MaxMind DB data format.
+type encoder struct {
+	b bytes.Buffer
+}
+
+// encode writes the value. Supported types are string, []byte, bool, float64, float32, int32, uint16, uint32,
+// uint64, int (written as uint32 or uint64 when non-negative, int32 otherwise), *big.Int, []interface{},
+// []string and map[string]interface{}. Map keys are written in sorted order, so equal maps encode equally.
+func (e *encoder) encode(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		e.control(typeString, len(v))
+		e.b.WriteString(v)
+	case []byte:
+		e.control(typeBytes, len(v))
+		e.b.Write(v)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		e.control(typeBool, size)
+	case float64:
+		e.control(typeDouble, 8)
+		_ = binary.Write(&e.b, binary.BigEndian, math.Float64bits(v))
+	case float32:
+		e.control(typeFloat, 4)
+		_ = binary.Write(&e.b, binary.BigEndian, math.Float32bits(v))
+	case int32:
+		e.unsigned(typeInt32, uint64(uint32(v)))
+	case uint16:
+		e.unsigned(typeUint16, uint64(v))
+	case uint32:
+		e.unsigned(typeUint32, uint64(v))
+	case uint64:
+		e.unsigned(typeUint64, v)
+	case int:
+		switch {
+		case v < 0 && v >= math.MinInt32:
+			e.unsigned(typeInt32, uint64(uint32(int32(v))))
+		case v < 0:
+			return fmt.Errorf("mmdb: integer %d out of range", v)
+		case uint64(v) <= math.MaxUint32:
+			e.unsigned(typeUint32, uint64(v))
+		default:
+			e.unsigned(typeUint64, uint64(v))
+		}
+	case *big.Int:
+		if v.Sign() < 0 || v.BitLen() > 128 {
+			return fmt.Errorf("mmdb: integer %s out of range", v)
+		}
+		b := v.Bytes()
+		e.control(typeUint128, len(b))
+		e.b.Write(b)
+	case []string:
+		e.control(typeArray, len(v))
+		for _, s := range v {
+			if err := e.encode(s); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		e.control(typeArray, len(v))
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		e.control(typeMap, len(keys))
+		for _, key := range keys {
+			if err := e.encode(key); err != nil {
+				return err
+			}
+			if err := e.encode(v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("mmdb: unsupported type %T", value)
+	}
+
+	return nil
+}
+
+// unsigned writes the integer of the type with leading zero bytes stripped.
+func (e *encoder) unsigned(typ int, v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+
+	b := bytes.TrimLeft(buf[:], "\x00")
+	e.control(typ, len(b))
+	e.b.Write(b)
+}
+
+// control writes the control byte of the type and size, followed by the extended type and size bytes.
+func (e *encoder) control(typ, size int) {
+	var ctrl byte
+	var extended []byte
+
+	if typ > typeMap {
+		extended = []byte{byte(typ - typeMap)}
+	} else {
+		ctrl = byte(typ) << 5
+	}
+
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+		ctrl |= byte(size)
+	case size < 29+256:
+		ctrl |= 29
+		sizeBytes = []byte{byte(size - 29)}
+	case size < 285+65536:
+		ctrl |= 30
+		s := size - 285
+		sizeBytes = []byte{byte(s >> 8), byte(s)}
+	default:
+		ctrl |= 31
+		s := size - 65821
+		sizeBytes = []byte{byte(s >> 16), byte(s >> 8), byte(s)}
+	}
+
+	e.b.WriteByte(ctrl)
+	e.b.Write(extended)
+	e.b.Write(sizeBytes)
+}
+
+// decoder reads values in the MaxMind DB data format.
+type decoder struct {
+	b []byte
+}
+
+// decode returns the value at the offset and the offset after it. Pointers are followed.
+func (d *decoder) decode(offset int) (interface{}, int, error) {
+	typ, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		value, _, err := d.decode(target)
+
+		return value, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			var key, value interface{}
+			if key, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+
+			s, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("mmdb: map key of type %T", key)
+			}
+
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+
+			m[s] = value
+		}
+
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			var value interface{}
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+
+			a = append(a, value)
+		}
+
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(d.b) {
+		return nil, 0, fmt.Errorf("mmdb: value at %d exceeds data section", offset)
+	}
+
+	payload := d.b[offset : offset+size]
+	next := offset + size
+
+	switch typ {
+	case typeString:
+		return string(payload), next, nil
+	case typeBytes:
+		return append([]byte(nil), payload...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("mmdb: invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("mmdb: invalid float size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(payload)), next, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("mmdb: invalid integer size %d", size)
+		}
+
+		var v uint64
+		for _, c := range payload {
+			v = v<<8 | uint64(c)
+		}
+
+		switch typ {
+		case typeUint16:
+			return uint16(v), next, nil
+		case typeUint32:
+			return uint32(v), next, nil
+		case typeInt32:
+			return int32(uint32(v)), next, nil
+		default:
+			return v, next, nil
+		}
+	case typeUint128:
+		return new(big.Int).SetBytes(payload), next, nil
+	default:
+		return nil, 0, fmt.Errorf("mmdb: unsupported type %d", typ)
+	}
+}
+
+// control returns the type and size of the value at the offset and the offset of its payload.
+func (d *decoder) control(offset int) (int, int, int, error) {
+	if offset >= len(d.b) {
+		return 0, 0, 0, fmt.Errorf("mmdb: offset %d exceeds data section", offset)
+	}
+
+	ctrl := d.b[offset]
+	offset++
+
+	typ := int(ctrl >> 5)
+	if typ == typePointer {
+		return typ, int(ctrl & 0x1f), offset, nil
+	}
+
+	if typ == typeExtended {
+		if offset >= len(d.b) {
+			return 0, 0, 0, fmt.Errorf("mmdb: truncated extended type")
+		}
+
+		typ = typeMap + int(d.b[offset])
+		offset++
+	}
+
+	size := int(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > len(d.b) {
+			return 0, 0, 0, fmt.Errorf("mmdb: truncated size")
+		}
+
+		var v int
+		for _, c := range d.b[offset : offset+n] {
+			v = v<<8 | int(c)
+		}
+		offset += n
+
+		switch size {
+		case 29:
+			size = 29 + v
+		case 30:
+			size = 285 + v
+		default:
+			size = 65821 + v
+		}
+	}
+
+	return typ, size, offset, nil
+}
+
+// pointer returns the target offset of the pointer with the size bits of its control byte,
+// and the offset after the pointer.
+func (d *decoder) pointer(bits, offset int) (int, int, error) {
+	n := (bits>>3)&0x3 + 1
+	if offset+n > len(d.b) {
+		return 0, 0, fmt.Errorf("mmdb: truncated pointer")
+	}
+
+	var v int
+	if n < 4 {
+		v = bits & 0x7
+	}
+
+	for _, c := range d.b[offset : offset+n] {
+		v = v<<8 | int(c)
+	}
+
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+
+	return v, offset + n, nil
+}
@@ -0,0 +1,348 @@
+package mmdb
+
+import (
+	"bytes"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// testInetnums is the list of netblocks written in tests.
+var testInetnums = []ipnetblocks.Inetnum{
+	{
+		Inetnum: "10.0.0.0 - 10.0.255.255",
+		Netname: "EXAMPLE-NET",
+		Country: "de",
+		Source:  "RIPE",
+		Org:     ipnetblocks.Organization{Name: "Example GmbH"},
+		AbuseContact: []ipnetblocks.Contact{
+			{Email: "Abuse@example.net"},
+		},
+	},
+	{
+		Inetnum: "10.0.0.0 - 10.0.255.255",
+		Parent:  "10.0.0.0 - 10.0.255.255",
+		AS:      ipnetblocks.AS{ASN: 64500, Name: "EXAMPLE-AS"},
+	},
+	{
+		Inetnum: "10.0.1.0 - 10.0.2.127",
+		Netname: "CUSTOMER-NET",
+		Source:  "RIPE",
+	},
+	{
+		Inetnum: "2001:db8:: - 2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+		Netname: "DOC-NET",
+		Country: "US",
+		Source:  "ARIN",
+		AS:      ipnetblocks.AS{ASN: 4200000000},
+	},
+	{Inetnum: "invalid"},
+}
+
+// TestWrite tests the Write function.
+func TestWrite(t *testing.T) {
+	base := map[string]interface{}{
+		"autonomous_system_number":       uint32(64500),
+		"autonomous_system_organization": "EXAMPLE-AS",
+		"organization":                   "Example GmbH",
+		"country": map[string]interface{}{
+			"iso_code": "DE",
+			"names":    map[string]interface{}{"en": "Germany"},
+		},
+		"netname":     "EXAMPLE-NET",
+		"abuse_email": "abuse@example.net",
+		"source":      "RIPE",
+	}
+
+	customer := make(map[string]interface{})
+	for key, value := range base {
+		customer[key] = value
+	}
+	customer["netname"] = "CUSTOMER-NET"
+
+	doc := map[string]interface{}{
+		"autonomous_system_number": uint32(4200000000),
+		"country": map[string]interface{}{
+			"iso_code": "US",
+			"names":    map[string]interface{}{"en": "United States of America"},
+		},
+		"netname": "DOC-NET",
+		"source":  "ARIN",
+	}
+
+	tests := []struct {
+		ip      string
+		record  map[string]interface{}
+		network string
+	}{
+		{"10.0.0.1", base, "10.0.0.0/24"},
+		{"10.0.1.200", customer, "10.0.1.0/24"},
+		{"10.0.2.1", customer, "10.0.2.0/25"},
+		{"10.0.2.200", base, "10.0.2.128/25"},
+		{"10.1.0.0", nil, "10.1.0.0/16"},
+		{"::ffff:10.0.1.1", customer, "10.0.1.0/24"},
+		{"2001:db8::1", doc, "2001:db8::/32"},
+		{"2001:db9::1", nil, "2001:db9::/32"},
+		{"2002:a00:101::1", customer, "2002:a00:100::/40"},
+	}
+
+	for _, recordSize := range []int{0, 24, 28, 32} {
+		var b bytes.Buffer
+		err := Write(&b, testInetnums, WriterParams{
+			RecordSize:  recordSize,
+			Description: "Test database",
+			BuildTime:   time.Unix(1650000000, 0),
+		})
+		if err != nil {
+			t.Fatalf("Write(RecordSize: %d) error = %v", recordSize, err)
+		}
+
+		r, err := FromBytes(b.Bytes())
+		if err != nil {
+			t.Fatalf("FromBytes(RecordSize: %d) error = %v", recordSize, err)
+		}
+
+		wantSize := recordSize
+		if wantSize == 0 {
+			wantSize = 24
+		}
+
+		if r.Metadata.RecordSize != wantSize || r.Metadata.IPVersion != 6 ||
+			r.Metadata.DatabaseType != "IP-Netblocks" || r.Metadata.BuildEpoch != 1650000000 ||
+			r.Metadata.BinaryFormatMajorVersion != 2 || r.Metadata.Description["en"] != "Test database" {
+			t.Errorf("Metadata = %+v", r.Metadata)
+		}
+
+		for _, tt := range tests {
+			record, network, err := r.Lookup(netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Errorf("Lookup(%s) error = %v", tt.ip, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(record, tt.record) {
+				t.Errorf("Lookup(%s) record = %v, want %v", tt.ip, record, tt.record)
+			}
+
+			if network.String() != tt.network {
+				t.Errorf("Lookup(%s) network = %v, want %v", tt.ip, network, tt.network)
+			}
+		}
+	}
+}
+
+// TestWriteFields tests the Write function with selected fields.
+func TestWriteFields(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, testInetnums, WriterParams{Fields: []Field{FieldASN}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := FromBytes(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, _, err := r.Lookup(netip.MustParseAddr("10.0.1.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]interface{}{"autonomous_system_number": uint32(64500)}; !reflect.DeepEqual(record, want) {
+		t.Errorf("Lookup() record = %v, want %v", record, want)
+	}
+
+	// Equal records of IPv4 prefixes are stored once, the IPv6 one differs.
+	if n := bytes.Count(r.data.b, []byte("autonomous_system_number")); n != 2 {
+		t.Errorf("data section contains %d records, want 2", n)
+	}
+
+	err = Write(&b, testInetnums, WriterParams{RecordSize: 20})
+	if err == nil || err.Error() != `invalid argument: "RecordSize" must be 24, 28 or 32` {
+		t.Errorf("Write(RecordSize: 20) error = %v", err)
+	}
+}
+
+// TestWriteOverlapping tests the Write function with a wider range which isn't prefix-aligned.
+func TestWriteOverlapping(t *testing.T) {
+	wider := ipnetblocks.Inetnum{
+		Inetnum: "10.0.0.1 - 10.0.0.254",
+		Netname: "NA",
+		Org:     ipnetblocks.Organization{Name: "OrgA"},
+	}
+	narrower := ipnetblocks.Inetnum{Inetnum: "10.0.0.0 - 10.0.0.127", Netname: "NB"}
+
+	tests := []struct {
+		ip   string
+		want map[string]interface{}
+	}{
+		{"10.0.0.0", map[string]interface{}{"netname": "NB"}},
+		{"10.0.0.5", map[string]interface{}{"netname": "NB", "organization": "OrgA"}},
+		{"10.0.0.127", map[string]interface{}{"netname": "NB", "organization": "OrgA"}},
+		{"10.0.0.200", map[string]interface{}{"netname": "NA", "organization": "OrgA"}},
+		{"10.0.0.255", nil},
+	}
+
+	for _, inetnums := range [][]ipnetblocks.Inetnum{{wider, narrower}, {narrower, wider}} {
+		var b bytes.Buffer
+		if err := Write(&b, inetnums, WriterParams{Fields: []Field{FieldOrg, FieldNetname}}); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := FromBytes(b.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range tests {
+			record, _, err := r.Lookup(netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Errorf("Lookup(%s) with %s first = %v, want %v", tt.ip, inetnums[0].Netname, record, tt.want)
+			}
+		}
+	}
+}
+
+// TestWriteEmpty tests the Write function without netblocks.
+func TestWriteEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, nil, WriterParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := FromBytes(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ip := range []string{"192.0.2.1", "2001:db8::1"} {
+		record, _, err := r.Lookup(netip.MustParseAddr(ip))
+		if err != nil || record != nil {
+			t.Errorf("Lookup(%s) = %v, %v, want nil", ip, record, err)
+		}
+	}
+}
+
+// TestFromBytes tests the FromBytes function with malformed databases.
+func TestFromBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"empty", nil, "mmdb: metadata not found"},
+		{"not a map", append(append([]byte(nil), metadataMarker...), 0x41, 'a'), "mmdb: metadata is not a map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromBytes(tt.b)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("FromBytes() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestEncode tests the encode and decode methods.
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"string", "netname", "netname"},
+		{"long string", strings.Repeat("a", 300), strings.Repeat("a", 300)},
+		{"huge string", strings.Repeat("b", 70000), strings.Repeat("b", 70000)},
+		{"bytes", []byte{1, 2}, []byte{1, 2}},
+		{"true", true, true},
+		{"false", false, false},
+		{"double", 1.5, 1.5},
+		{"float", float32(2.5), float32(2.5)},
+		{"int32", int32(-7), int32(-7)},
+		{"uint16", uint16(443), uint16(443)},
+		{"zero uint32", uint32(0), uint32(0)},
+		{"uint64", uint64(1) << 40, uint64(1) << 40},
+		{"int", 64500, uint32(64500)},
+		{"negative int", -1, int32(-1)},
+		{"uint128", new(big.Int).Lsh(big.NewInt(1), 100), new(big.Int).Lsh(big.NewInt(1), 100)},
+		{"array", []string{"en", "de"}, []interface{}{"en", "de"}},
+		{"map", map[string]interface{}{"a": []interface{}{true}}, map[string]interface{}{"a": []interface{}{true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e encoder
+			if err := e.encode(tt.value); err != nil {
+				t.Fatal(err)
+			}
+
+			d := decoder{b: e.b.Bytes()}
+			got, next, err := d.decode(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode() = %v, want %v", got, tt.want)
+			}
+
+			if next != e.b.Len() {
+				t.Errorf("decode() next = %d, want %d", next, e.b.Len())
+			}
+		})
+	}
+
+	var e encoder
+	if err := e.encode(struct{}{}); err == nil {
+		t.Errorf("encode(struct{}{}) expected error")
+	}
+}
+
+// TestDecodePointer tests the decode method with pointers.
+func TestDecodePointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		pointer []byte
+		target  int
+	}{
+		{"11-bit", []byte{0x20 | 0x01, 0x02}, 0x102},
+		{"19-bit", []byte{0x28 | 0x01, 0x02, 0x03}, 0x10203 + 2048},
+		{"27-bit", []byte{0x30 | 0x01, 0x02, 0x03, 0x04}, 0x1020304 + 526336},
+		{"32-bit", []byte{0x38, 0x01, 0x02, 0x03, 0x04}, 0x1020304},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decoder{b: tt.pointer}
+			target, next, err := d.pointer(int(tt.pointer[0]&0x1f), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if target != tt.target || next != len(tt.pointer) {
+				t.Errorf("pointer() = %d, %d, want %d, %d", target, next, tt.target, len(tt.pointer))
+			}
+		})
+	}
+
+	// A map with the key "a" whose value points to the string at offset 0.
+	d := decoder{b: []byte{0x41, 'x', 0xe1, 0x41, 'a', 0x20, 0x00}}
+	got, next, err := d.decode(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]interface{}{"a": "x"}; !reflect.DeepEqual(got, want) || next != len(d.b) {
+		t.Errorf("decode() = %v, %d, want %v, %d", got, next, want, len(d.b))
+	}
+}
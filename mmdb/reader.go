@@ -0,0 +1,229 @@
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+)
+
+// Metadata is the metadata of a database.
+type Metadata struct {
+	// NodeCount is the number of nodes of the search tree.
+	NodeCount int
+
+	// RecordSize is the size of search tree records in bits.
+	RecordSize int
+
+	// IPVersion is 4 for IPv4-only databases, or 6 for databases which may contain IPv6 addresses.
+	IPVersion int
+
+	// DatabaseType is the type of the database, e.g. "IP-Netblocks".
+	DatabaseType string
+
+	// Languages is the list of locale codes of names in records.
+	Languages []string
+
+	// BinaryFormatMajorVersion is the major version of the database format.
+	BinaryFormatMajorVersion int
+
+	// BinaryFormatMinorVersion is the minor version of the database format.
+	BinaryFormatMinorVersion int
+
+	// BuildEpoch is the build time of the database in seconds since the Unix epoch.
+	BuildEpoch uint64
+
+	// Description is the description of the database by locale code.
+	Description map[string]string
+}
+
+// Reader reads a database.
+type Reader struct {
+	// Metadata is the metadata of the database.
+	Metadata Metadata
+
+	tree      []byte
+	data      decoder
+	nodeSize  int
+	ipv4Start int
+	ipv4Depth int
+}
+
+// Open reads the database from the file.
+func Open(path string) (*Reader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBytes(b)
+}
+
+// FromBytes reads the database from the buffer.
+func FromBytes(b []byte) (*Reader, error) {
+	i := bytes.LastIndex(b, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("mmdb: metadata not found")
+	}
+
+	meta := decoder{b: b[i+len(metadataMarker):]}
+	value, _, err := meta.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("mmdb: cannot decode metadata: %w", err)
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("mmdb: metadata is not a map")
+	}
+
+	r := &Reader{Metadata: parseMetadata(m)}
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("mmdb: unsupported record size %d", r.Metadata.RecordSize)
+	}
+
+	if r.Metadata.BinaryFormatMajorVersion != 2 {
+		return nil, fmt.Errorf("mmdb: unsupported format version %d", r.Metadata.BinaryFormatMajorVersion)
+	}
+
+	r.nodeSize = r.Metadata.RecordSize / 4
+	treeSize := r.Metadata.NodeCount * r.nodeSize
+	if treeSize+dataSectionSeparator > i {
+		return nil, errors.New("mmdb: search tree exceeds the file")
+	}
+
+	r.tree = b[:treeSize]
+	r.data = decoder{b: b[treeSize+dataSectionSeparator : i]}
+
+	if r.Metadata.IPVersion == 6 {
+		// IPv4 addresses are looked up in the ::/96 subtree.
+		for r.ipv4Depth < 96 && r.ipv4Start < r.Metadata.NodeCount {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+			r.ipv4Depth++
+		}
+	}
+
+	return r, nil
+}
+
+// Lookup returns the record of the IP address and the network it belongs to. The record is nil when the IP
+// address isn't in the database. Maps are returned as map[string]interface{}, arrays as []interface{}, and
+// unsigned integers as uint16, uint32, uint64 or *big.Int.
+func (r *Reader) Lookup(ip netip.Addr) (map[string]interface{}, netip.Prefix, error) {
+	ip = ip.WithZone("")
+	if ip.Is4In6() {
+		ip = ip.Unmap()
+	}
+
+	if !ip.IsValid() {
+		return nil, netip.Prefix{}, errors.New("mmdb: invalid IP address")
+	}
+
+	if ip.Is6() && r.Metadata.IPVersion == 4 {
+		return nil, netip.Prefix{}, fmt.Errorf("mmdb: cannot look up %s in an IPv4 database", ip)
+	}
+
+	addr := ip.AsSlice()
+	n := 0
+	if ip.Is4() {
+		n = r.ipv4Start
+	}
+
+	depth := 0
+	for ; depth < len(addr)*8 && n < r.Metadata.NodeCount; depth++ {
+		n = r.record(n, int(addr[depth/8]>>(7-depth%8))&1)
+	}
+
+	network, _ := ip.Prefix(depth)
+	if ip.Is4() && r.ipv4Depth < 96 {
+		// The tree ends above the ::/96 subtree, so the IPv6 network covers all IPv4 addresses.
+		network = netip.PrefixFrom(netip.IPv6Unspecified(), r.ipv4Depth)
+	}
+
+	switch {
+	case n == r.Metadata.NodeCount:
+		return nil, network, nil
+	case n < r.Metadata.NodeCount:
+		return nil, netip.Prefix{}, errors.New("mmdb: search tree is deeper than addresses")
+	}
+
+	value, _, err := r.data.decode(n - r.Metadata.NodeCount - dataSectionSeparator)
+	if err != nil {
+		return nil, netip.Prefix{}, err
+	}
+
+	rec, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, netip.Prefix{}, fmt.Errorf("mmdb: record of type %T", value)
+	}
+
+	return rec, network, nil
+}
+
+// record returns the left (0) or right (1) record of the node.
+func (r *Reader) record(n, side int) int {
+	b := r.tree[n*r.nodeSize : (n+1)*r.nodeSize]
+
+	switch r.Metadata.RecordSize {
+	case 24:
+		b = b[side*3:]
+		return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+	case 28:
+		if side == 0 {
+			return int(b[3]>>4)<<24 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3]&0x0f)<<24 | int(b[4])<<16 | int(b[5])<<8 | int(b[6])
+	default:
+		b = b[side*4:]
+		return int(b[0])<<24 | int(b[1])<<16 | int(b[2])<<8 | int(b[3])
+	}
+}
+
+// parseMetadata returns the metadata from the decoded map.
+func parseMetadata(m map[string]interface{}) Metadata {
+	meta := Metadata{
+		NodeCount:                int(unsigned(m["node_count"])),
+		RecordSize:               int(unsigned(m["record_size"])),
+		IPVersion:                int(unsigned(m["ip_version"])),
+		BinaryFormatMajorVersion: int(unsigned(m["binary_format_major_version"])),
+		BinaryFormatMinorVersion: int(unsigned(m["binary_format_minor_version"])),
+		BuildEpoch:               unsigned(m["build_epoch"]),
+	}
+
+	meta.DatabaseType, _ = m["database_type"].(string)
+
+	languages, _ := m["languages"].([]interface{})
+	for _, language := range languages {
+		if s, ok := language.(string); ok {
+			meta.Languages = append(meta.Languages, s)
+		}
+	}
+
+	description, _ := m["description"].(map[string]interface{})
+	meta.Description = make(map[string]string, len(description))
+	for locale, text := range description {
+		if s, ok := text.(string); ok {
+			meta.Description[locale] = s
+		}
+	}
+
+	return meta
+}
+
+// unsigned returns the decoded unsigned integer, or zero for other types.
+func unsigned(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	}
+
+	return 0
+}
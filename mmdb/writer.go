@@ -0,0 +1,457 @@
+// Package mmdb writes netblocks to MaxMind DB files, which can be loaded by libmaxminddb, the GeoIP2 libraries
+// and web server modules, and reads them back.
+package mmdb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"sort"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/internal/defaults"
+)
+
+// Field is a key of the record written for a netblock.
+type Field string
+
+const (
+	// FieldASN is the AS number, as in GeoLite2-ASN databases.
+	FieldASN Field = "autonomous_system_number"
+
+	// FieldASName is the AS name, as in GeoLite2-ASN databases.
+	FieldASName Field = "autonomous_system_organization"
+
+	// FieldOrg is the organization name.
+	FieldOrg Field = "organization"
+
+	// FieldCountry is the map with the ISO 3166-1 alpha-2 "iso_code" and English "names", as in GeoIP2 databases.
+	FieldCountry Field = "country"
+
+	// FieldNetname is the netblock name.
+	FieldNetname Field = "netname"
+
+	// FieldAbuseEmail is the first abuse contact email of the netblock.
+	FieldAbuseEmail Field = "abuse_email"
+
+	// FieldSource is the registry the netblock was taken from.
+	FieldSource Field = "source"
+)
+
+// AllFields is the list of all fields written by default.
+var AllFields = []Field{FieldASN, FieldASName, FieldOrg, FieldCountry, FieldNetname, FieldAbuseEmail, FieldSource}
+
+// metadataMarker separates the data section from the metadata.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the number of zero bytes between the search tree and the data section.
+const dataSectionSeparator = 16
+
+// WriterParams is used to write databases. None of parameters are mandatory.
+type WriterParams struct {
+	// Fields is the list of fields of records. Default: AllFields.
+	Fields []Field
+
+	// DatabaseType is the type of the database in the metadata. Default: "IP-Netblocks".
+	DatabaseType string
+
+	// Description is the English description of the database in the metadata.
+	Description string
+
+	// RecordSize is the size of search tree records in bits: 24, 28 or 32. Default: the smallest which fits.
+	RecordSize int
+
+	// BuildTime is the build time of the database in the metadata. Default: the current time.
+	BuildTime time.Time
+}
+
+// Write writes the database mapping ranges of the netblocks to their records. A narrower netblock wins over
+// a wider one, and fields missing in its record are taken from the wider one. Among netblocks with the same
+// range a registry-sourced one wins over a BGP-derived one. IPv4 ranges are stored in the ::/96 subtree which
+// is also aliased from ::ffff:0:0/96 and 2002::/16. Netblocks with unparsable ranges are ignored.
+func Write(w io.Writer, inetnums []ipnetblocks.Inetnum, params WriterParams) error {
+	switch params.RecordSize {
+	case 0, 24, 28, 32:
+	default:
+		return &ipnetblocks.ArgError{Name: "RecordSize", Message: "must be 24, 28 or 32"}
+	}
+
+	fields := params.Fields
+	if fields == nil {
+		fields = AllFields
+	}
+
+	t := &tree{root: &node{}}
+	for _, e := range sortedEntries(inetnums) {
+		rec := newRecord(e.inetnum, fields)
+		for _, prefix := range e.rng.Prefixes() {
+			t.insert(prefix, rec)
+		}
+	}
+	t.alias()
+
+	nodes := t.nodes()
+
+	var data encoder
+	offsets := make(map[string]int)
+	values := make(map[*node][2]uint64, len(nodes))
+	index := make(map[*node]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	var maxValue uint64
+	for _, n := range nodes {
+		var v [2]uint64
+		for i, r := range n.records {
+			switch {
+			case r.node != nil:
+				v[i] = uint64(index[r.node])
+			case r.value == nil:
+				v[i] = uint64(len(nodes))
+			default:
+				var e encoder
+				if err := e.encode(r.value); err != nil {
+					return err
+				}
+
+				offset, ok := offsets[e.b.String()]
+				if !ok {
+					offset = data.b.Len()
+					offsets[e.b.String()] = offset
+					data.b.Write(e.b.Bytes())
+				}
+
+				v[i] = uint64(len(nodes) + dataSectionSeparator + offset)
+			}
+
+			if v[i] > maxValue {
+				maxValue = v[i]
+			}
+		}
+		values[n] = v
+	}
+
+	recordSize := params.RecordSize
+	if recordSize == 0 {
+		recordSize = 24
+		for recordSize < 32 && maxValue >= 1<<recordSize {
+			recordSize += 4
+		}
+	}
+
+	if maxValue >= 1<<recordSize {
+		return fmt.Errorf("mmdb: database does not fit %d-bit records", recordSize)
+	}
+
+	var b bytes.Buffer
+	for _, n := range nodes {
+		writeNode(&b, values[n], recordSize)
+	}
+
+	b.Write(make([]byte, dataSectionSeparator))
+	b.Write(data.b.Bytes())
+	b.Write(metadataMarker)
+
+	buildTime := params.BuildTime
+	if buildTime.IsZero() {
+		buildTime = time.Now()
+	}
+
+	description := map[string]interface{}{}
+	if params.Description != "" {
+		description["en"] = params.Description
+	}
+
+	var meta encoder
+	err := meta.encode(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(buildTime.Unix()),
+		"database_type":               defaults.String(params.DatabaseType, "IP-Netblocks"),
+		"description":                 description,
+		"ip_version":                  uint16(6),
+		"languages":                   []string{"en"},
+		"node_count":                  uint32(len(nodes)),
+		"record_size":                 uint16(recordSize),
+	})
+	if err != nil {
+		return err
+	}
+
+	b.Write(meta.b.Bytes())
+
+	_, err = w.Write(b.Bytes())
+
+	return err
+}
+
+// writeNode writes the node with two records of the size.
+func writeNode(b *bytes.Buffer, v [2]uint64, recordSize int) {
+	l, r := v[0], v[1]
+
+	switch recordSize {
+	case 24:
+		b.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(r >> 16), byte(r >> 8), byte(r)})
+	case 28:
+		b.Write([]byte{
+			byte(l >> 16), byte(l >> 8), byte(l),
+			byte(l>>24&0x0f)<<4 | byte(r>>24&0x0f),
+			byte(r >> 16), byte(r >> 8), byte(r),
+		})
+	default:
+		b.Write([]byte{
+			byte(l >> 24), byte(l >> 16), byte(l >> 8), byte(l),
+			byte(r >> 24), byte(r >> 16), byte(r >> 8), byte(r),
+		})
+	}
+}
+
+// entry is a netblock with its parsed range.
+type entry struct {
+	inetnum ipnetblocks.Inetnum
+	rng     ipnetblocks.Range
+	size    *big.Int
+}
+
+// sortedEntries returns netblocks with parsable ranges, wider ones first and BGP-derived ones before
+// registry-sourced ones with the same size, so that later insertions win.
+func sortedEntries(inetnums []ipnetblocks.Inetnum) []entry {
+	var entries []entry
+
+	for _, inetnum := range inetnums {
+		r, err := inetnum.Range()
+		if err != nil {
+			continue
+		}
+
+		size := new(big.Int).SetBytes(r.Last.AsSlice())
+		size.Sub(size, new(big.Int).SetBytes(r.First.AsSlice()))
+
+		entries = append(entries, entry{inetnum: inetnum, rng: r, size: size})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if c := entries[i].size.Cmp(entries[j].size); c != 0 {
+			return c > 0
+		}
+
+		return entries[i].inetnum.Parent != "" && entries[j].inetnum.Parent == ""
+	})
+
+	return entries
+}
+
+// newRecord returns the record of the netblock with the fields which have values.
+func newRecord(inetnum ipnetblocks.Inetnum, fields []Field) map[string]interface{} {
+	rec := make(map[string]interface{})
+
+	for _, field := range fields {
+		switch field {
+		case FieldASN:
+			if inetnum.AS.ASN > 0 {
+				rec[string(field)] = uint32(inetnum.AS.ASN)
+			}
+		case FieldASName:
+			setString(rec, field, inetnum.AS.Name)
+		case FieldOrg:
+			setString(rec, field, inetnum.Org.Name)
+		case FieldCountry:
+			if country, ok := ipnetblocks.LookupCountry(inetnum.Country); ok {
+				rec[string(field)] = map[string]interface{}{
+					"iso_code": country.Code,
+					"names":    map[string]interface{}{"en": country.Name},
+				}
+			}
+		case FieldNetname:
+			setString(rec, field, inetnum.Netname)
+		case FieldAbuseEmail:
+			if abuse := ipnetblocks.ResolveAbuse([]ipnetblocks.Inetnum{inetnum}); len(abuse.Emails) > 0 {
+				rec[string(field)] = abuse.Emails[0]
+			}
+		case FieldSource:
+			setString(rec, field, string(inetnum.Registry()))
+		}
+	}
+
+	return rec
+}
+
+// setString sets the field of the record unless the value is empty.
+func setString(rec map[string]interface{}, field Field, value string) {
+	if value != "" {
+		rec[string(field)] = value
+	}
+}
+
+// record is a search tree record pointing to a node, to a data value, or to nothing.
+type record struct {
+	node  *node
+	value map[string]interface{}
+}
+
+// node is a search tree node.
+type node struct {
+	records [2]record
+}
+
+// tree is the binary search tree of the IPv6 address space.
+type tree struct {
+	root *node
+}
+
+// insert sets the records of the prefix to the value. Values already set inside the prefix, including those of
+// narrower prefixes split off before, only fill fields missing in the value.
+func (t *tree) insert(prefix netip.Prefix, value map[string]interface{}) {
+	addr, bits := treePath(prefix)
+	if bits == 0 {
+		// The whole address space is covered by both records of the root.
+		t.insert(netip.PrefixFrom(netip.IPv6Unspecified(), 1), value)
+		t.insert(netip.MustParsePrefix("8000::/1"), value)
+		return
+	}
+
+	n := t.root
+	for depth := 0; ; depth++ {
+		r := &n.records[bit(addr, depth)]
+
+		if depth == bits-1 {
+			r.overlay(value)
+			return
+		}
+
+		if r.node == nil {
+			// A narrower prefix splits the record, both halves keep its value.
+			r.node = &node{records: [2]record{{value: r.value}, {value: r.value}}}
+			r.value = nil
+		}
+
+		n = r.node
+	}
+}
+
+// overlay sets every leaf under the record to the value with missing fields taken from the leaf.
+func (r *record) overlay(value map[string]interface{}) {
+	if r.node == nil {
+		r.value = merge(value, r.value)
+		return
+	}
+
+	r.node.records[0].overlay(value)
+	r.node.records[1].overlay(value)
+}
+
+// alias points ::ffff:0:0/96 and 2002::/16 to the IPv4 subtree, unless they hold data of their own.
+func (t *tree) alias() {
+	ipv4, ok := t.lookup(netip.PrefixFrom(netip.IPv6Unspecified(), 96))
+	if !ok || (ipv4.node == nil && ipv4.value == nil) {
+		return
+	}
+
+	for _, prefix := range []netip.Prefix{
+		netip.MustParsePrefix("::ffff:0:0/96"),
+		netip.MustParsePrefix("2002::/16"),
+	} {
+		addr, bits := treePath(prefix)
+
+		n := t.root
+		for depth := 0; ; depth++ {
+			r := &n.records[bit(addr, depth)]
+
+			if depth == bits-1 {
+				if r.node == nil && r.value == nil {
+					*r = ipv4
+				}
+				break
+			}
+
+			if r.value != nil {
+				break
+			}
+
+			if r.node == nil {
+				r.node = &node{}
+			}
+
+			n = r.node
+		}
+	}
+}
+
+// lookup returns the record at the end of the prefix path, if it exists.
+func (t *tree) lookup(prefix netip.Prefix) (record, bool) {
+	addr, bits := treePath(prefix)
+
+	n := t.root
+	for depth := 0; ; depth++ {
+		r := n.records[bit(addr, depth)]
+		if depth == bits-1 {
+			return r, true
+		}
+
+		if r.node == nil {
+			return record{}, false
+		}
+
+		n = r.node
+	}
+}
+
+// nodes returns the nodes of the tree in the breadth-first order, starting from the root.
+// Aliased nodes are listed once.
+func (t *tree) nodes() []*node {
+	nodes := []*node{t.root}
+	seen := map[*node]bool{t.root: true}
+
+	for i := 0; i < len(nodes); i++ {
+		for _, r := range nodes[i].records {
+			if r.node != nil && !seen[r.node] {
+				seen[r.node] = true
+				nodes = append(nodes, r.node)
+			}
+		}
+	}
+
+	return nodes
+}
+
+// treePath returns the IPv6 address and the prefix length of the prefix in the tree. IPv4 prefixes are
+// placed in the ::/96 subtree.
+func treePath(prefix netip.Prefix) ([16]byte, int) {
+	prefix = prefix.Masked()
+	if prefix.Addr().Is4() {
+		var addr [16]byte
+		v4 := prefix.Addr().As4()
+		copy(addr[12:], v4[:])
+
+		return addr, prefix.Bits() + 96
+	}
+
+	return prefix.Addr().As16(), prefix.Bits()
+}
+
+// bit returns the bit of the address at the depth.
+func bit(addr [16]byte, depth int) int {
+	return int(addr[depth/8]>>(7-depth%8)) & 1
+}
+
+// merge returns the record with missing fields taken from the fallback one.
+func merge(value, fallback map[string]interface{}) map[string]interface{} {
+	if len(fallback) == 0 {
+		return value
+	}
+
+	merged := make(map[string]interface{}, len(fallback))
+	for key, v := range fallback {
+		merged[key] = v
+	}
+
+	for key, v := range value {
+		merged[key] = v
+	}
+
+	return merged
+}
@@ -58,6 +58,7 @@ type AS struct {
 
 	// Type is the autonomous system type. One of the following: "Cable/DSL/ISP", "Content", "Educational/Research",
 	// "Enterprise", "Non-Profit", "Not Disclosed", "NSP", "Route Server". Empty when unknown.
+	// Use ASType to get it with variants converted to the listed types.
 	Type string `json:"type"`
 
 	// Route is the autonomous system route.
@@ -207,7 +208,8 @@ type Inetnum struct {
 	// Remarks is remarks and comments associated with the IP Netblock.
 	Remarks []string `json:"remarks"`
 
-	// Source is the source of range.
+	// Source is the source of range, e.g. the regional internet registry.
+	// Use Registry to get it with variants converted to the listed registries.
 	Source string `json:"source"`
 }
 
@@ -241,6 +243,12 @@ type IPNetblocksResponse struct {
 
 	// Error is the error message. This field is omitted when a call is successful.
 	Error string `json:"error"`
+
+	// Local indicates that the response was generated by the client without calling the API.
+	Local bool `json:"-"`
+
+	// Normalizations is the list of changes made to the request input before sending it to the API.
+	Normalizations []Normalization `json:"-"`
 }
 
 // ErrorMessage is an error message.
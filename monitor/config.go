@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Duration is time.Duration encoded in JSON as a string like "1h30m".
+type Duration time.Duration
+
+// UnmarshalJSON decodes the duration from a string accepted by time.ParseDuration.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Target is the watched autonomous system or organization. Exactly one of ASN and Org must be set.
+type Target struct {
+	// Name is the unique name of the target used in events and the state file.
+	Name string `json:"name"`
+
+	// ASN is the watched autonomous system number.
+	ASN int `json:"asn,omitempty"`
+
+	// Org is the watched organization name.
+	Org string `json:"org,omitempty"`
+
+	// Interval is the time between polls of the target. Default: Config.Interval.
+	Interval Duration `json:"interval,omitempty"`
+}
+
+// Webhook is the URL change events are posted to.
+type Webhook struct {
+	// URL is the endpoint accepting POST requests with JSON events.
+	URL string `json:"url"`
+
+	// Secret is the key events are signed with. Events are not signed when it's empty.
+	Secret string `json:"secret,omitempty"`
+
+	// MaxRetries is the number of retries of a failed delivery. Default: 3.
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// RetryDelay is the delay before the first retry, doubled for each next one. Default: 1s.
+	RetryDelay Duration `json:"retryDelay,omitempty"`
+}
+
+// Config is the list of watched targets and webhooks, usually loaded from a JSON file.
+type Config struct {
+	// Targets is the list of watched autonomous systems and organizations.
+	Targets []Target `json:"targets"`
+
+	// Webhooks is the list of URLs every change event is posted to.
+	Webhooks []Webhook `json:"webhooks"`
+
+	// StatePath is the file the last-known netblocks of targets are kept in.
+	StatePath string `json:"statePath"`
+
+	// Interval is the default time between polls of a target. Default: 24h.
+	Interval Duration `json:"interval,omitempty"`
+}
+
+// defaultInterval is the default time between polls of a target.
+const defaultInterval = Duration(24 * time.Hour)
+
+// LoadConfig reads and validates the JSON config file.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseConfig(f)
+}
+
+// ParseConfig parses and validates the JSON config.
+func ParseConfig(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var config Config
+	if err := dec.Decode(&config); err != nil {
+		return nil, fmt.Errorf("cannot parse config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate checks that targets are unique and well-formed and that webhooks have URLs.
+func (c *Config) Validate() error {
+	if len(c.Targets) == 0 {
+		return &ipnetblocks.ArgError{Name: "targets", Message: "can not be empty"}
+	}
+
+	if c.StatePath == "" {
+		return &ipnetblocks.ArgError{Name: "statePath", Message: "can not be empty"}
+	}
+
+	names := make(map[string]bool, len(c.Targets))
+	for _, target := range c.Targets {
+		if target.Name == "" {
+			return &ipnetblocks.ArgError{Name: "name", Message: "can not be empty"}
+		}
+
+		if names[target.Name] {
+			return &ipnetblocks.ArgError{Name: target.Name, Message: "is duplicate target name"}
+		}
+		names[target.Name] = true
+
+		if (target.ASN == 0) == (target.Org == "") {
+			return &ipnetblocks.ArgError{Name: target.Name, Message: "must have either asn or org"}
+		}
+	}
+
+	for _, webhook := range c.Webhooks {
+		if webhook.URL == "" {
+			return &ipnetblocks.ArgError{Name: "url", Message: "can not be empty"}
+		}
+	}
+
+	return nil
+}
+
+// interval returns the time between polls of the target.
+func (c *Config) interval(target Target) time.Duration {
+	switch {
+	case target.Interval > 0:
+		return time.Duration(target.Interval)
+	case c.Interval > 0:
+		return time.Duration(c.Interval)
+	default:
+		return time.Duration(defaultInterval)
+	}
+}
@@ -0,0 +1,296 @@
+// Package monitor watches autonomous systems and organizations for netblock changes and posts change events
+// to webhooks.
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+	"github.com/whois-api-llc/ip-netblocks-go/snapshot"
+)
+
+// userAgent is the User-Agent header of webhook requests.
+const userAgent = "ip-netblocks-go-monitor"
+
+// Event is the change of netblocks of a target posted to webhooks.
+type Event struct {
+	// Target is the name of the target.
+	Target string `json:"target"`
+
+	// ASN is the watched autonomous system number.
+	ASN int `json:"asn,omitempty"`
+
+	// Org is the watched organization name.
+	Org string `json:"org,omitempty"`
+
+	// Time is the time of the poll which detected the change.
+	Time time.Time `json:"time"`
+
+	// Diff is the list of added, removed and modified netblocks since the previous poll.
+	snapshot.Diff
+}
+
+// Params is used to create Monitor. None of parameters are mandatory.
+type Params struct {
+	// HTTPClient is the client used to post events to webhooks. Default: http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Logger receives poll errors in Run. Default: log.Default().
+	Logger *log.Logger
+}
+
+// Monitor polls targets through the IP Netblocks API and posts their changes to webhooks.
+type Monitor struct {
+	service ipnetblocks.IPNetblocks
+	config  Config
+	client  *http.Client
+	logger  *log.Logger
+	now     func() time.Time
+
+	state *state
+}
+
+// state is the last-known netblocks of targets persisted between runs.
+type state struct {
+	Targets map[string]*targetState `json:"targets"`
+}
+
+// targetState is the last-known netblocks of a target.
+type targetState struct {
+	// Checked is the time of the last successful poll.
+	Checked time.Time `json:"checked"`
+
+	// Inetnums is the list of netblocks found by the last successful poll.
+	Inetnums []ipnetblocks.Inetnum `json:"inetnums"`
+}
+
+// New creates Monitor which sends requests through the specified service.
+func New(service ipnetblocks.IPNetblocks, config Config, params Params) (*Monitor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := http.DefaultClient
+	if params.HTTPClient != nil {
+		client = params.HTTPClient
+	}
+
+	logger := log.Default()
+	if params.Logger != nil {
+		logger = params.Logger
+	}
+
+	return &Monitor{
+		service: service,
+		config:  config,
+		client:  client,
+		logger:  logger,
+		now:     time.Now,
+	}, nil
+}
+
+// Run polls every target when its interval has passed since the last poll, until the context is canceled.
+// Targets never polled before are polled immediately. Poll errors are logged, and the failed target is
+// retried after its interval.
+func (m *Monitor) Run(ctx context.Context) error {
+	if err := m.loadState(); err != nil {
+		return err
+	}
+
+	failed := make(map[string]time.Time)
+
+	for {
+		var next time.Time
+
+		for _, target := range m.config.Targets {
+			due := m.due(target, failed[target.Name])
+			if !due.After(m.now()) {
+				if _, err := m.Poll(ctx, target); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+
+					m.logger.Printf("monitor: %s: %v", target.Name, err)
+					failed[target.Name] = m.now()
+				} else {
+					delete(failed, target.Name)
+				}
+
+				due = m.due(target, failed[target.Name])
+			}
+
+			if next.IsZero() || due.Before(next) {
+				next = due
+			}
+		}
+
+		timer := time.NewTimer(next.Sub(m.now()))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// due returns the time of the next poll of the target.
+func (m *Monitor) due(target Target, failed time.Time) time.Time {
+	last := failed
+	if ts, ok := m.state.Targets[target.Name]; ok && ts.Checked.After(last) {
+		last = ts.Checked
+	}
+
+	if last.IsZero() {
+		return m.now()
+	}
+
+	return last.Add(m.config.interval(target))
+}
+
+// Poll fetches the netblocks of the target and compares them with the last-known ones. When they differ,
+// the change event is posted to all webhooks and returned. The first poll of a target only records its
+// netblocks and returns nil. The state is saved only after the event is delivered to all webhooks, so that
+// an undelivered change is detected again by the next poll.
+func (m *Monitor) Poll(ctx context.Context, target Target) (*Event, error) {
+	if m.state == nil {
+		if err := m.loadState(); err != nil {
+			return nil, err
+		}
+	}
+
+	inetnums, err := m.fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+
+	previous, ok := m.state.Targets[target.Name]
+	if !ok {
+		m.state.Targets[target.Name] = &targetState{Checked: now, Inetnums: inetnums}
+		return nil, m.saveState()
+	}
+
+	diff := snapshot.Compare(previous.Inetnums, inetnums)
+	if diff.IsEmpty() {
+		previous.Checked = now
+		return nil, m.saveState()
+	}
+
+	event := &Event{Target: target.Name, ASN: target.ASN, Org: target.Org, Time: now, Diff: *diff}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every webhook gets the event even if delivery to another one fails. The first failure is returned.
+	var deliveryErr error
+	for _, webhook := range m.config.Webhooks {
+		if err = m.deliver(ctx, webhook, body); err != nil && deliveryErr == nil {
+			deliveryErr = err
+		}
+	}
+
+	if deliveryErr != nil {
+		return event, deliveryErr
+	}
+
+	m.state.Targets[target.Name] = &targetState{Checked: now, Inetnums: inetnums}
+
+	return event, m.saveState()
+}
+
+// fetch returns all netblocks of the target, following pages of results.
+func (m *Monitor) fetch(ctx context.Context, target Target) ([]ipnetblocks.Inetnum, error) {
+	var inetnums []ipnetblocks.Inetnum
+	var from *string
+
+	for {
+		opts := []ipnetblocks.Option{ipnetblocks.OptionLimit(1000), ipnetblocks.OptionFrom(from)}
+
+		var resp *ipnetblocks.IPNetblocksResponse
+		var err error
+
+		if target.ASN != 0 {
+			resp, _, err = m.service.GetByASN(ctx, target.ASN, opts...)
+		} else {
+			resp, _, err = m.service.GetByOrg(ctx, target.Org, opts...)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		inetnums = append(inetnums, resp.Result.Inetnums...)
+
+		next := resp.Result.Next
+		if next == nil || (from != nil && *next == *from) {
+			return inetnums, nil
+		}
+
+		from = next
+	}
+}
+
+// loadState reads the state file. A missing file means that no target was polled yet.
+func (m *Monitor) loadState() error {
+	m.state = &state{Targets: make(map[string]*targetState)}
+
+	b, err := os.ReadFile(m.config.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(b, m.state); err != nil {
+		return err
+	}
+
+	if m.state.Targets == nil {
+		m.state.Targets = make(map[string]*targetState)
+	}
+
+	return nil
+}
+
+// saveState atomically replaces the state file.
+func (m *Monitor) saveState() error {
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m.state); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.config.StatePath), filepath.Base(m.config.StatePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(b.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), m.config.StatePath)
+}
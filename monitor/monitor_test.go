@@ -0,0 +1,287 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// fakeService answers GetByASN and GetByOrg with the configured pages.
+type fakeService struct {
+	ipnetblocks.IPNetblocks
+
+	// pages is keyed by "AS<asn>" or the organization name, suffixed with "|<from>" for next pages.
+	pages map[string]ipnetblocks.Result
+}
+
+// response returns the page for the target.
+func (s *fakeService) response(key string, opts []ipnetblocks.Option) *ipnetblocks.IPNetblocksResponse {
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	if from := q.Get("from"); from != "" {
+		key += "|" + from
+	}
+
+	return &ipnetblocks.IPNetblocksResponse{Search: key, Result: s.pages[key]}
+}
+
+// GetByASN returns the page for the ASN.
+func (s *fakeService) GetByASN(
+	_ context.Context,
+	asn int,
+	opts ...ipnetblocks.Option,
+) (*ipnetblocks.IPNetblocksResponse, *ipnetblocks.Response, error) {
+	return s.response("AS"+strconv.Itoa(asn), opts), nil, nil
+}
+
+// GetByOrg returns the page for the organization.
+func (s *fakeService) GetByOrg(
+	_ context.Context,
+	org string,
+	opts ...ipnetblocks.Option,
+) (*ipnetblocks.IPNetblocksResponse, *ipnetblocks.Response, error) {
+	return s.response(org, opts), nil, nil
+}
+
+// webhookServer records delivered events, failing the first failures requests with 503.
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	requests int
+	events   []Event
+	verified []bool
+}
+
+// newWebhookServer starts the webhook stand-in.
+func newWebhookServer(secret string, failures int) *webhookServer {
+	s := &webhookServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests++
+		if s.requests <= s.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			panic(err)
+		}
+
+		var event Event
+		if err = json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.events = append(s.events, event)
+		s.verified = append(s.verified, Verify(secret, req.Header, body))
+	}))
+
+	return s
+}
+
+// inetnum returns the sample netblock.
+func inetnum(r, netname string) ipnetblocks.Inetnum {
+	return ipnetblocks.Inetnum{Inetnum: r, Netname: netname, Source: "ARIN"}
+}
+
+// TestMonitorPoll tests the Monitor.Poll function.
+func TestMonitorPoll(t *testing.T) {
+	ctx := context.Background()
+
+	webhook := newWebhookServer("s3cret", 2)
+	defer webhook.Close()
+
+	service := &fakeService{pages: map[string]ipnetblocks.Result{
+		"AS15169": {
+			Next:     func() *string { s := "8.8.4.0 - 8.8.4.255"; return &s }(),
+			Inetnums: []ipnetblocks.Inetnum{inetnum("8.8.4.0 - 8.8.4.255", "GOGL")},
+		},
+		"AS15169|8.8.4.0 - 8.8.4.255": {
+			Inetnums: []ipnetblocks.Inetnum{inetnum("8.8.8.0 - 8.8.8.255", "LVLT-GOGL-8-8-8")},
+		},
+	}}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	noRetries := 0
+
+	config := Config{
+		Targets: []Target{{Name: "google", ASN: 15169}},
+		Webhooks: []Webhook{
+			{URL: webhook.URL, Secret: "s3cret", RetryDelay: Duration(time.Millisecond)},
+		},
+		StatePath: statePath,
+	}
+
+	m, err := New(service, config, Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := m.Poll(ctx, config.Targets[0])
+	if err != nil || event != nil {
+		t.Fatalf("first Poll() got = %v, %v", event, err)
+	}
+
+	event, err = m.Poll(ctx, config.Targets[0])
+	if err != nil || event != nil {
+		t.Fatalf("unchanged Poll() got = %v, %v", event, err)
+	}
+
+	service.pages["AS15169|8.8.4.0 - 8.8.4.255"] = ipnetblocks.Result{
+		Inetnums: []ipnetblocks.Inetnum{inetnum("8.8.9.0 - 8.8.9.255", "NEW")},
+	}
+
+	// A new monitor picks the state up from the file.
+	m, err = New(service, config, Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err = m.Poll(ctx, config.Targets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event == nil || len(event.Added) != 1 || len(event.Removed) != 1 || event.Added[0].Netname != "NEW" {
+		t.Fatalf("Poll() got = %+v", event)
+	}
+
+	if webhook.requests != 3 || len(webhook.events) != 1 || !webhook.verified[0] {
+		t.Errorf("webhook got %d requests, events %+v, verified %v", webhook.requests, webhook.events, webhook.verified)
+	}
+
+	got := webhook.events[0]
+	if got.Target != "google" || got.ASN != 15169 || got.Removed[0].Netname != "LVLT-GOGL-8-8-8" {
+		t.Errorf("delivered event = %+v", got)
+	}
+
+	// An undelivered change is detected again by the next poll.
+	service.pages["AS15169|8.8.4.0 - 8.8.4.255"] = ipnetblocks.Result{}
+	webhook.failures = webhook.requests + 1
+	config.Webhooks[0].MaxRetries = &noRetries
+
+	m, err = New(service, config, Params{Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.Poll(ctx, config.Targets[0])
+	if err == nil || !strings.Contains(err.Error(), "delivery failed after 1 attempts: unexpected status: 503") {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	event, err = m.Poll(ctx, config.Targets[0])
+	if err != nil || event == nil || len(event.Removed) != 1 || event.Removed[0].Netname != "NEW" {
+		t.Fatalf("Poll() got = %+v, %v", event, err)
+	}
+}
+
+// TestMonitorFetchOrg tests that all pages of organization results are fetched.
+func TestMonitorFetchOrg(t *testing.T) {
+	service := &fakeService{pages: map[string]ipnetblocks.Result{
+		"Example Inc.": {
+			Next:     func() *string { s := "192.0.2.0 - 192.0.2.255"; return &s }(),
+			Inetnums: []ipnetblocks.Inetnum{inetnum("192.0.2.0 - 192.0.2.255", "EXAMPLE-1")},
+		},
+		"Example Inc.|192.0.2.0 - 192.0.2.255": {
+			Inetnums: []ipnetblocks.Inetnum{inetnum("198.51.100.0 - 198.51.100.255", "EXAMPLE-2")},
+		},
+	}}
+
+	config := Config{
+		Targets:   []Target{{Name: "example", Org: "Example Inc."}},
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	m, err := New(service, config, Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inetnums, err := m.fetch(context.Background(), config.Targets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inetnums) != 2 || inetnums[1].Netname != "EXAMPLE-2" {
+		t.Errorf("fetch() = %+v, want both pages", inetnums)
+	}
+}
+
+// TestParseConfig tests the ParseConfig function.
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(strings.NewReader(`{
+		"targets": [
+			{"name": "google", "asn": 15169, "interval": "1h"},
+			{"name": "example", "org": "Example Inc."}
+		],
+		"webhooks": [{"url": "https://hooks.example.com/netblocks", "secret": "s3cret", "maxRetries": 5}],
+		"statePath": "/var/lib/ipnetblocks/state.json",
+		"interval": "12h"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := config.interval(config.Targets[0]); got != time.Hour {
+		t.Errorf("interval() got = %v", got)
+	}
+
+	if got := config.interval(config.Targets[1]); got != 12*time.Hour {
+		t.Errorf("interval() got = %v", got)
+	}
+
+	if *config.Webhooks[0].MaxRetries != 5 {
+		t.Errorf("MaxRetries got = %v", *config.Webhooks[0].MaxRetries)
+	}
+
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{
+			`{"targets": [{"name": "x", "asn": 1, "org": "y"}], "statePath": "s"}`,
+			`invalid argument: "x" must have either asn or org`,
+		},
+		{
+			`{"targets": [{"name": "x", "asn": 1}, {"name": "x", "asn": 2}], "statePath": "s"}`,
+			`invalid argument: "x" is duplicate target name`,
+		},
+		{
+			`{"targets": [{"name": "x", "asn": 1}]}`,
+			`invalid argument: "statePath" can not be empty`,
+		},
+		{
+			`{"targets": [{"name": "x", "asn": 1, "interval": "daily"}], "statePath": "s"}`,
+			`cannot parse config: time: invalid duration "daily"`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err = ParseConfig(strings.NewReader(tt.config))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("ParseConfig() error = %v, wantErr %v", err, tt.err)
+		}
+	}
+}
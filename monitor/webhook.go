@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimestampHeader is the header with the Unix time the event was signed at.
+	TimestampHeader = "X-IPNetblocks-Timestamp"
+
+	// SignatureHeader is the header with the "sha256=" prefixed hex HMAC-SHA256 of the timestamp, a dot and
+	// the request body, keyed with the webhook secret.
+	SignatureHeader = "X-IPNetblocks-Signature"
+)
+
+// defaultMaxRetries is the default number of retries of a failed delivery.
+const defaultMaxRetries = 3
+
+// defaultRetryDelay is the default delay before the first retry.
+const defaultRetryDelay = Duration(time.Second)
+
+// Sign returns the signature of the body sent at the timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the request headers carry the valid signature of the body. Receivers should also
+// reject timestamps too far from the current time to prevent replays.
+func Verify(secret string, header http.Header, body []byte) bool {
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal([]byte(header.Get(SignatureHeader)), []byte(Sign(secret, timestamp, body)))
+}
+
+// DeliveryError is returned when the event could not be delivered to the webhook after all retries.
+type DeliveryError struct {
+	// URL is the webhook URL.
+	URL string
+
+	// Attempts is the number of made attempts.
+	Attempts int
+
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// Error returns error message as a string.
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("webhook %s: delivery failed after %d attempts: %v", e.URL, e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// deliver posts the body to the webhook, retrying network errors, 429 and 5xx responses with exponential backoff.
+func (m *Monitor) deliver(ctx context.Context, webhook Webhook, body []byte) error {
+	maxRetries := defaultMaxRetries
+	if webhook.MaxRetries != nil {
+		maxRetries = *webhook.MaxRetries
+	}
+
+	delay := time.Duration(defaultRetryDelay)
+	if webhook.RetryDelay > 0 {
+		delay = time.Duration(webhook.RetryDelay)
+	}
+
+	var err error
+	attempts := 0
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+
+		attempts++
+
+		var retry bool
+		if retry, err = m.post(ctx, webhook, body); err == nil || !retry {
+			break
+		}
+	}
+
+	if err != nil {
+		return &DeliveryError{URL: webhook.URL, Attempts: attempts, Err: err}
+	}
+
+	return nil
+}
+
+// post makes a single delivery attempt and reports whether a failed attempt may be retried.
+func (m *Monitor) post(ctx context.Context, webhook Webhook, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	if webhook.Secret != "" {
+		timestamp := m.now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+	return retry, fmt.Errorf("unexpected status: %s", resp.Status)
+}
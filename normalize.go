@@ -0,0 +1,213 @@
+package ipnetblocks
+
+import (
+	"math/bits"
+	"net"
+	"net/netip"
+	"strconv"
+)
+
+// NormalizationKind is the kind of change made to the request input.
+type NormalizationKind string
+
+const (
+	// NormalizationMaskCanonicalized means that a non-canonical mask was replaced with the mask of its
+	// leading ones.
+	NormalizationMaskCanonicalized NormalizationKind = "mask canonicalized"
+
+	// NormalizationHostBitsCleared means that host bits of the CIDR address were cleared.
+	NormalizationHostBitsCleared NormalizationKind = "host bits cleared"
+
+	// NormalizationIPv4Unmapped means that an IPv4-mapped IPv6 address was converted to IPv4.
+	NormalizationIPv4Unmapped NormalizationKind = "IPv4-mapped address unwrapped"
+
+	// NormalizationZoneStripped means that the IPv6 zone was removed from the address.
+	NormalizationZoneStripped NormalizationKind = "zone stripped"
+
+	// NormalizationIPv6Truncated means that an IPv6 address was truncated to the configured prefix length.
+	NormalizationIPv6Truncated NormalizationKind = "IPv6 address truncated"
+)
+
+// Normalization is a change made to the request input before sending it to the API. A net.IP passed to GetByIP
+// can carry neither a zone nor the IPv4-mapped form, so NormalizationZoneStripped and NormalizationIPv4Unmapped
+// are only reported by NormalizeIP and NormalizePrefix, and the latter by GetByCIDR for IPv4-mapped prefixes.
+type Normalization struct {
+	// Kind is the kind of change.
+	Kind NormalizationKind
+
+	// From is the value before the change.
+	From string
+
+	// To is the value after the change.
+	To string
+}
+
+// InputParams defines how GetByIP and GetByCIDR normalize and validate their input.
+// Leaving this struct empty fixes the input and never rejects it.
+type InputParams struct {
+	// Strict makes non-canonical masks and CIDRs with host bits set rejected instead of fixed.
+	Strict bool
+
+	// IPv6PrefixLen truncates IPv6 addresses to the prefix of the specified length for privacy, so that the API
+	// never sees the interface identifier. Zero disables truncation.
+	IPv6PrefixLen int
+}
+
+// NormalizeIP unwraps IPv4-mapped addresses, strips zones and truncates IPv6 addresses if configured.
+func (p InputParams) NormalizeIP(ip netip.Addr) (netip.Addr, []Normalization, error) {
+	if !ip.IsValid() {
+		return ip, nil, &ArgError{"ip", "can not be empty"}
+	}
+
+	var normalizations []Normalization
+
+	if ip.Zone() != "" {
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationZoneStripped,
+			From: ip.String(),
+			To:   ip.WithZone("").String(),
+		})
+		ip = ip.WithZone("")
+	}
+
+	if ip.Is4In6() {
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationIPv4Unmapped,
+			From: ip.String(),
+			To:   ip.Unmap().String(),
+		})
+		ip = ip.Unmap()
+	}
+
+	if ip.Is6() && p.IPv6PrefixLen > 0 && p.IPv6PrefixLen < ip.BitLen() {
+		truncated := netip.PrefixFrom(ip, p.IPv6PrefixLen).Masked().Addr()
+		if truncated != ip {
+			normalizations = append(normalizations, Normalization{
+				Kind: NormalizationIPv6Truncated,
+				From: ip.String(),
+				To:   truncated.String(),
+			})
+			ip = truncated
+		}
+	}
+
+	return ip, normalizations, nil
+}
+
+// NormalizePrefix unwraps IPv4-mapped prefixes, clears host bits and truncates IPv6 prefixes if configured.
+// In the strict mode a prefix with host bits set is rejected.
+func (p InputParams) NormalizePrefix(prefix netip.Prefix) (netip.Prefix, []Normalization, error) {
+	if !prefix.IsValid() {
+		return prefix, nil, &ArgError{"ip", "can not be empty"}
+	}
+
+	var normalizations []Normalization
+
+	if prefix.Addr().Is4In6() {
+		if prefix.Bits() < 96 {
+			return prefix, nil, &ArgError{prefix.String(), "is invalid IPv4-mapped CIDR"}
+		}
+
+		unmapped := netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationIPv4Unmapped,
+			From: prefix.String(),
+			To:   unmapped.String(),
+		})
+		prefix = unmapped
+	}
+
+	if masked := prefix.Masked(); masked != prefix {
+		if p.Strict {
+			return prefix, nil, &ArgError{prefix.String(), "has host bits set"}
+		}
+
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationHostBitsCleared,
+			From: prefix.String(),
+			To:   masked.String(),
+		})
+		prefix = masked
+	}
+
+	if prefix.Addr().Is6() && p.IPv6PrefixLen > 0 && p.IPv6PrefixLen < prefix.Bits() {
+		truncated := netip.PrefixFrom(prefix.Addr(), p.IPv6PrefixLen).Masked()
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationIPv6Truncated,
+			From: prefix.String(),
+			To:   truncated.String(),
+		})
+		prefix = truncated
+	}
+
+	return prefix, normalizations, nil
+}
+
+// normalizeIPNet converts net.IPNet to the normalized prefix. A non-canonical mask is replaced with the mask of
+// its leading ones, or rejected in the strict mode.
+func (p InputParams) normalizeIPNet(ip net.IPNet) (netip.Prefix, []Normalization, error) {
+	addr, ok := netip.AddrFromSlice(ip.IP)
+	if !ok {
+		return netip.Prefix{}, nil, &ArgError{ip.IP.String(), "is invalid IP address"}
+	}
+
+	if len(ip.Mask) != net.IPv4len && len(ip.Mask) != net.IPv6len {
+		return netip.Prefix{}, nil, &ArgError{ip.Mask.String(), "is invalid mask"}
+	}
+
+	var normalizations []Normalization
+
+	ones, size := ip.Mask.Size()
+	if size == 0 {
+		ones = leadingOnes(ip.Mask)
+		if p.Strict {
+			return netip.Prefix{}, nil, &ArgError{ip.Mask.String(), "is non-canonical mask"}
+		}
+
+		normalizations = append(normalizations, Normalization{
+			Kind: NormalizationMaskCanonicalized,
+			From: ip.Mask.String(),
+			To:   net.CIDRMask(ones, len(ip.Mask)*8).String(),
+		})
+	}
+
+	// net.IP stores IPv4 addresses in the IPv4-mapped form, so only the mask tells which family was meant.
+	if len(ip.Mask) == net.IPv4len {
+		if !addr.Unmap().Is4() {
+			return netip.Prefix{}, nil, &ArgError{ip.IP.String() + "/" + strconv.Itoa(ones), "is invalid CIDR"}
+		}
+		addr = addr.Unmap()
+	} else if addr.Is4() {
+		addr = netip.AddrFrom16(addr.As16())
+	}
+
+	prefix := netip.PrefixFrom(addr, ones)
+	if !prefix.IsValid() {
+		return netip.Prefix{}, nil, &ArgError{ip.IP.String() + "/" + strconv.Itoa(ones), "is invalid CIDR"}
+	}
+
+	prefix, prefixNormalizations, err := p.NormalizePrefix(prefix)
+	if err != nil {
+		return netip.Prefix{}, nil, err
+	}
+
+	return prefix, append(normalizations, prefixNormalizations...), nil
+}
+
+// leadingOnes returns the number of leading one bits of the mask.
+func leadingOnes(mask net.IPMask) int {
+	n := 0
+	for _, b := range mask {
+		n += bits.LeadingZeros8(^b)
+		if b != 0xff {
+			break
+		}
+	}
+
+	return n
+}
+
+// prefixQuery returns the "ip" and "mask" query values for the prefix.
+func prefixQuery(prefix netip.Prefix) (string, string) {
+	return prefix.Addr().String(), strconv.Itoa(prefix.Bits())
+}
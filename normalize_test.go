@@ -0,0 +1,251 @@
+package ipnetblocks
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestInputParamsNormalizeIP tests the InputParams.NormalizeIP function.
+func TestInputParamsNormalizeIP(t *testing.T) {
+	tests := []struct {
+		name   string
+		params InputParams
+		ip     string
+		want   string
+		kinds  []NormalizationKind
+	}{
+		{
+			name: "unchanged",
+			ip:   "8.8.8.8",
+			want: "8.8.8.8",
+		},
+		{
+			name:  "IPv4-mapped",
+			ip:    "::ffff:8.8.8.8",
+			want:  "8.8.8.8",
+			kinds: []NormalizationKind{NormalizationIPv4Unmapped},
+		},
+		{
+			name:  "zone",
+			ip:    "fe80::1%eth0",
+			want:  "fe80::1",
+			kinds: []NormalizationKind{NormalizationZoneStripped},
+		},
+		{
+			name:   "truncated",
+			params: InputParams{IPv6PrefixLen: 48},
+			ip:     "2001:db8:1:2:3:4:5:6",
+			want:   "2001:db8:1::",
+			kinds:  []NormalizationKind{NormalizationIPv6Truncated},
+		},
+		{
+			name:   "IPv4 is not truncated",
+			params: InputParams{IPv6PrefixLen: 48},
+			ip:     "8.8.8.8",
+			want:   "8.8.8.8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, normalizations, err := tt.params.NormalizeIP(netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var kinds []NormalizationKind
+			for _, n := range normalizations {
+				kinds = append(kinds, n.Kind)
+			}
+
+			if got.String() != tt.want || !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("InputParams.NormalizeIP() = %v %v, want %v %v", got, kinds, tt.want, tt.kinds)
+			}
+		})
+	}
+}
+
+// TestInputParamsNormalizeIPNet tests the InputParams.normalizeIPNet function.
+func TestInputParamsNormalizeIPNet(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  InputParams
+		ip      net.IPNet
+		want    string
+		kinds   []NormalizationKind
+		wantErr string
+	}{
+		{
+			name: "canonical IPv4",
+			ip:   net.IPNet{IP: net.ParseIP("8.8.8.0"), Mask: net.CIDRMask(24, 32)},
+			want: "8.8.8.0/24",
+		},
+		{
+			name:  "host bits",
+			ip:    net.IPNet{IP: net.ParseIP("8.8.8.8"), Mask: net.CIDRMask(24, 32)},
+			want:  "8.8.8.0/24",
+			kinds: []NormalizationKind{NormalizationHostBitsCleared},
+		},
+		{
+			name:    "host bits strict",
+			params:  InputParams{Strict: true},
+			ip:      net.IPNet{IP: net.ParseIP("8.8.8.8"), Mask: net.CIDRMask(24, 32)},
+			wantErr: `invalid argument: "8.8.8.8/24" has host bits set`,
+		},
+		{
+			name:  "non-canonical mask",
+			ip:    net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPMask{0xff, 0xff, 0x00, 0xff}},
+			want:  "10.0.0.0/16",
+			kinds: []NormalizationKind{NormalizationMaskCanonicalized},
+		},
+		{
+			name:    "non-canonical mask strict",
+			params:  InputParams{Strict: true},
+			ip:      net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPMask{0xff, 0xff, 0x00, 0xff}},
+			wantErr: `invalid argument: "ffff00ff" is non-canonical mask`,
+		},
+		{
+			name:  "IPv4-mapped",
+			ip:    net.IPNet{IP: net.ParseIP("::ffff:10.1.0.0"), Mask: net.CIDRMask(112, 128)},
+			want:  "10.1.0.0/16",
+			kinds: []NormalizationKind{NormalizationIPv4Unmapped},
+		},
+		{
+			name:   "IPv6 truncated",
+			params: InputParams{IPv6PrefixLen: 48},
+			ip:     net.IPNet{IP: net.ParseIP("2001:db8:1:2::"), Mask: net.CIDRMask(64, 128)},
+			want:   "2001:db8:1::/48",
+			kinds:  []NormalizationKind{NormalizationIPv6Truncated},
+		},
+		{
+			name:    "invalid address",
+			ip:      net.IPNet{IP: net.IP{10, 0, 0}, Mask: net.CIDRMask(8, 32)},
+			wantErr: `invalid argument: "?0a0000" is invalid IP address`,
+		},
+		{
+			name:    "family mismatch",
+			ip:      net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(8, 32)},
+			wantErr: `invalid argument: "2001:db8::/8" is invalid CIDR`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, normalizations, err := tt.params.normalizeIPNet(tt.ip)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+
+			var kinds []NormalizationKind
+			for _, n := range normalizations {
+				kinds = append(kinds, n.Kind)
+			}
+
+			if got.String() != tt.want || !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("InputParams.normalizeIPNet() = %v %v, want %v %v", got, kinds, tt.want, tt.kinds)
+			}
+		})
+	}
+}
+
+// TestIPNetblocksNormalizations tests that GetByCIDR sends the normalized input and reports changes.
+func TestIPNetblocksNormalizations(t *testing.T) {
+	server := pagedServer(map[string]string{
+		"8.8.8.0/24": `{"search":"8.8.8.0/24","result":{"count":1,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"8.8.8.0 - 8.8.8.255","source":"ARIN"}]}}`,
+	})
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:         server.Client(),
+		IPNetblocksBaseURL: apiURL,
+	})
+
+	got, _, err := api.GetByCIDR(context.Background(),
+		net.IPNet{IP: net.ParseIP("::ffff:8.8.8.8"), Mask: net.CIDRMask(120, 128)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Normalization{
+		{Kind: NormalizationIPv4Unmapped, From: "::ffff:8.8.8.8/120", To: "8.8.8.8/24"},
+		{Kind: NormalizationHostBitsCleared, From: "8.8.8.8/24", To: "8.8.8.0/24"},
+	}
+
+	if got.Result.Count != 1 || !reflect.DeepEqual(got.Normalizations, want) {
+		t.Errorf("IPNetblocks.GetByCIDR() got = %+v, want normalizations %+v", got, want)
+	}
+}
+
+// TestIPNetblocksGetRawByCIDRNormalizations tests that GetRawByCIDR sends the normalized input.
+func TestIPNetblocksGetRawByCIDRNormalizations(t *testing.T) {
+	server := pagedServer(map[string]string{
+		"8.8.0.0/16": `{"search":"8.8.0.0/16","result":{"count":1,"limit":100,"from":null,"next":null,
+"inetnums":[{"inetnum":"8.8.0.0 - 8.8.255.255","source":"ARIN"}]}}`,
+	})
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		ip      net.IPNet
+		want    string
+		wantErr string
+	}{
+		{
+			name: "non-canonical mask",
+			ip:   net.IPNet{IP: net.IP{8, 8, 8, 8}, Mask: net.IPMask{0xff, 0xff, 0x00, 0xff}},
+			want: `"search":"8.8.0.0/16"`,
+		},
+		{
+			name: "mapped prefix",
+			ip:   net.IPNet{IP: net.ParseIP("::ffff:8.8.0.0"), Mask: net.CIDRMask(112, 128)},
+			want: `"search":"8.8.0.0/16"`,
+		},
+		{
+			name:    "non-canonical mask in strict mode",
+			strict:  true,
+			ip:      net.IPNet{IP: net.IP{8, 8, 8, 8}, Mask: net.IPMask{0xff, 0xff, 0x00, 0xff}},
+			wantErr: `invalid argument: "ffff00ff" is non-canonical mask`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewClient(apiKey, ClientParams{
+				HTTPClient:         server.Client(),
+				IPNetblocksBaseURL: apiURL,
+				Input:              InputParams{Strict: tt.strict},
+			})
+
+			resp, err := api.GetRawByCIDR(context.Background(), tt.ip)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("IPNetblocks.GetRawByCIDR() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(resp.Body), tt.want) {
+				t.Errorf("IPNetblocks.GetRawByCIDR() got = %s, want %v", resp.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package ipnetblocks
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Range is an inclusive range of IP addresses of the same family.
+type Range struct {
+	// First is the first address of the range.
+	First netip.Addr
+
+	// Last is the last address of the range.
+	Last netip.Addr
+}
+
+// ParseRange parses the IP range in the "first - last" format used by the Inetnum field.
+// The CIDR notation is accepted as well.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return Range{}, &ArgError{s, "is invalid IP range"}
+		}
+
+		return PrefixRange(prefix), nil
+	}
+
+	firstString, lastString, found := strings.Cut(s, "-")
+	if !found {
+		return Range{}, &ArgError{s, "is invalid IP range"}
+	}
+
+	first, err := netip.ParseAddr(strings.TrimSpace(firstString))
+	if err != nil {
+		return Range{}, &ArgError{s, "is invalid IP range"}
+	}
+
+	last, err := netip.ParseAddr(strings.TrimSpace(lastString))
+	if err != nil {
+		return Range{}, &ArgError{s, "is invalid IP range"}
+	}
+
+	r := Range{
+		First: first.Unmap().WithZone(""),
+		Last:  last.Unmap().WithZone(""),
+	}
+
+	if !r.IsValid() {
+		return Range{}, &ArgError{s, "is invalid IP range"}
+	}
+
+	return r, nil
+}
+
+// PrefixRange returns the range of addresses covered by the prefix.
+func PrefixRange(prefix netip.Prefix) Range {
+	prefix = prefix.Masked()
+
+	return Range{
+		First: prefix.Addr(),
+		Last:  lastAddr(prefix),
+	}
+}
+
+// IsValid reports whether both addresses are set, belong to the same family, and First is not after Last.
+func (r Range) IsValid() bool {
+	return r.First.IsValid() && r.Last.IsValid() &&
+		r.First.BitLen() == r.Last.BitLen() &&
+		r.First.Compare(r.Last) <= 0
+}
+
+// Contains reports whether the address belongs to the range.
+func (r Range) Contains(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return r.IsValid() && ip.BitLen() == r.First.BitLen() &&
+		r.First.Compare(ip) <= 0 && ip.Compare(r.Last) <= 0
+}
+
+// String returns the range in the "first - last" format.
+func (r Range) String() string {
+	return r.First.String() + " - " + r.Last.String()
+}
+
+// Prefixes returns the minimal list of prefixes which exactly cover the range, in address order.
+func (r Range) Prefixes() []netip.Prefix {
+	if !r.IsValid() {
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+
+	first := r.First
+	for {
+		bits := first.BitLen()
+		for bits > 0 {
+			wider := netip.PrefixFrom(first, bits-1).Masked()
+			if wider.Addr() != first || lastAddr(wider).Compare(r.Last) > 0 {
+				break
+			}
+			bits--
+		}
+
+		prefix := netip.PrefixFrom(first, bits)
+		prefixes = append(prefixes, prefix)
+
+		last := lastAddr(prefix)
+		if last.Compare(r.Last) >= 0 {
+			return prefixes
+		}
+
+		first = last.Next()
+	}
+}
+
+// Range returns the parsed IP range of the netblock.
+func (i Inetnum) Range() (Range, error) {
+	return ParseRange(i.Inetnum)
+}
+
+// lastAddr returns the last address of the prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr()
+
+	if addr.Is4() {
+		b := addr.As4()
+		setHostBits(b[:], prefix.Bits())
+		return netip.AddrFrom4(b)
+	}
+
+	b := addr.As16()
+	setHostBits(b[:], prefix.Bits())
+	return netip.AddrFrom16(b)
+}
+
+// setHostBits sets all bits after the first n bits of the address.
+func setHostBits(b []byte, n int) {
+	for i := n; i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+}
+
+// prefixIPNet converts the prefix to net.IPNet.
+func prefixIPNet(prefix netip.Prefix) net.IPNet {
+	return net.IPNet{
+		IP:   net.IP(prefix.Addr().AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}
+}
+
+// Key returns the key used to deduplicate netblocks returned by several requests. Netblocks with the same range,
+// parent and source have the same key.
+func (i Inetnum) Key() string {
+	return i.Inetnum + "|" + i.Parent + "|" + i.Source
+}
@@ -0,0 +1,161 @@
+package ipnetblocks
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+// TestParseRange tests the ParseRange function.
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "8.8.8.0 - 8.8.8.255",
+			want: "8.8.8.0 - 8.8.8.255",
+		},
+		{
+			name: "8.8.8.0-8.8.8.255",
+			want: "8.8.8.0 - 8.8.8.255",
+		},
+		{
+			name: "8.8.8.8/24",
+			want: "8.8.8.0 - 8.8.8.255",
+		},
+		{
+			name: "::ffff:10.0.0.1 - ::ffff:10.0.0.9",
+			want: "10.0.0.1 - 10.0.0.9",
+		},
+		{
+			name: "2001:db8:: - 2001:db8::ffff",
+			want: "2001:db8:: - 2001:db8::ffff",
+		},
+		{
+			name:    "8.8.8.255 - 8.8.8.0",
+			wantErr: `invalid argument: "8.8.8.255 - 8.8.8.0" is invalid IP range`,
+		},
+		{
+			name:    "8.8.8.0 - 2001:db8::",
+			wantErr: `invalid argument: "8.8.8.0 - 2001:db8::" is invalid IP range`,
+		},
+		{
+			name:    "8.8.8.0",
+			wantErr: `invalid argument: "8.8.8.0" is invalid IP range`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRange(tt.name)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("ParseRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRangePrefixes tests the Range.Prefixes function.
+func TestRangePrefixes(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "8.8.8.0 - 8.8.8.255",
+			want: []string{"8.8.8.0/24"},
+		},
+		{
+			name: "10.0.0.1 - 10.0.0.10",
+			want: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/31", "10.0.0.10/32"},
+		},
+		{
+			name: "0.0.0.0 - 255.255.255.255",
+			want: []string{"0.0.0.0/0"},
+		},
+		{
+			name: "192.168.0.0 - 192.168.2.255",
+			want: []string{"192.168.0.0/23", "192.168.2.0/24"},
+		},
+		{
+			name: "2001:db8:: - 2001:db8::1:0",
+			want: []string{"2001:db8::/112", "2001:db8::1:0/128"},
+		},
+		{
+			name: ":: - ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+			want: []string{"::/0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRange(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, prefix := range r.Prefixes() {
+				got = append(got, prefix.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Range.Prefixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRangeContains tests the Range.Contains function.
+func TestRangeContains(t *testing.T) {
+	r, err := ParseRange("8.8.8.0 - 8.8.8.255")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.0", true},
+		{"8.8.8.255", true},
+		{"::ffff:8.8.8.8", true},
+		{"8.8.9.0", false},
+		{"::808:808", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := r.Contains(netip.MustParseAddr(tt.ip)); got != tt.want {
+				t.Errorf("Range.Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestInetnumKey tests the Inetnum.Key function.
+func TestInetnumKey(t *testing.T) {
+	inetnum := Inetnum{Inetnum: "8.8.8.0 - 8.8.8.255", Source: "ARIN", Netname: "GOGL"}
+
+	tests := []struct {
+		name  string
+		other Inetnum
+		want  bool
+	}{
+		{"same", Inetnum{Inetnum: "8.8.8.0 - 8.8.8.255", Source: "ARIN", Netname: "LVLT-GOGL-8-8-8"}, true},
+		{"other source", Inetnum{Inetnum: "8.8.8.0 - 8.8.8.255", Source: "RADB"}, false},
+		{"bgp", Inetnum{Inetnum: "8.8.8.0 - 8.8.8.255", Source: "ARIN", Parent: "8.0.0.0 - 8.127.255.255"}, false},
+		{"other range", Inetnum{Inetnum: "8.8.4.0 - 8.8.4.255", Source: "ARIN"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inetnum.Key() == tt.other.Key(); got != tt.want {
+				t.Errorf("Inetnum.Key() equal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
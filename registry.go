@@ -0,0 +1,257 @@
+package ipnetblocks
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ASType is the autonomous system type. Values not listed below are kept as is.
+type ASType string
+
+const (
+	// ASTypeUnknown means that the type is not known.
+	ASTypeUnknown ASType = ""
+
+	// ASTypeISP is the access provider: cable, DSL, or other ISP.
+	ASTypeISP ASType = "Cable/DSL/ISP"
+
+	// ASTypeContent is the content provider or hosting.
+	ASTypeContent ASType = "Content"
+
+	// ASTypeEducational is the educational or research network.
+	ASTypeEducational ASType = "Educational/Research"
+
+	// ASTypeEnterprise is the enterprise network.
+	ASTypeEnterprise ASType = "Enterprise"
+
+	// ASTypeNonProfit is the non-profit organization.
+	ASTypeNonProfit ASType = "Non-Profit"
+
+	// ASTypeNotDisclosed means that the network has not disclosed its type.
+	ASTypeNotDisclosed ASType = "Not Disclosed"
+
+	// ASTypeNSP is the network service provider, e.g. a transit or a backbone network.
+	ASTypeNSP ASType = "NSP"
+
+	// ASTypeRouteServer is the route server of an internet exchange.
+	ASTypeRouteServer ASType = "Route Server"
+)
+
+// asTypeVariants maps the spellings of AS types, lowercased and without punctuation, to the types.
+var asTypeVariants = map[string]ASType{
+	"cabledslisp":         ASTypeISP,
+	"isp":                 ASTypeISP,
+	"content":             ASTypeContent,
+	"educationalresearch": ASTypeEducational,
+	"educational":         ASTypeEducational,
+	"education":           ASTypeEducational,
+	"research":            ASTypeEducational,
+	"enterprise":          ASTypeEnterprise,
+	"nonprofit":           ASTypeNonProfit,
+	"notdisclosed":        ASTypeNotDisclosed,
+	"nsp":                 ASTypeNSP,
+	"routeserver":         ASTypeRouteServer,
+}
+
+// ParseASType returns the AS type ignoring case, spaces and punctuation, e.g. "cable/dsl/isp" or "non profit".
+// An unrecognized value is returned as is.
+func ParseASType(s string) ASType {
+	s = strings.TrimSpace(s)
+	if t, ok := asTypeVariants[variantKey(s)]; ok {
+		return t
+	}
+
+	return ASType(s)
+}
+
+// IsKnown reports whether the type is one of the listed ones.
+func (t ASType) IsKnown() bool {
+	known, ok := asTypeVariants[variantKey(string(t))]
+
+	return ok && known == t
+}
+
+// String returns the type as the API does.
+func (t ASType) String() string {
+	return string(t)
+}
+
+// UnmarshalJSON decodes the type and converts its variants to the listed types.
+func (t *ASType) UnmarshalJSON(b []byte) error {
+	str, err := unmarshalString(b)
+	if err != nil {
+		return err
+	}
+
+	*t = ParseASType(str)
+	return nil
+}
+
+// MarshalJSON encodes the type as the API does.
+func (t ASType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(t))
+}
+
+// Registry is the source of the netblock data, e.g. a regional internet registry. Values not listed below are
+// kept as is.
+type Registry string
+
+const (
+	// RegistryUnknown means that the source is not known.
+	RegistryUnknown Registry = ""
+
+	// RegistryAFRINIC is the African Network Information Centre.
+	RegistryAFRINIC Registry = "AFRINIC"
+
+	// RegistryAPNIC is the Asia-Pacific Network Information Centre.
+	RegistryAPNIC Registry = "APNIC"
+
+	// RegistryARIN is the American Registry for Internet Numbers.
+	RegistryARIN Registry = "ARIN"
+
+	// RegistryLACNIC is the Latin America and Caribbean Network Information Centre.
+	RegistryLACNIC Registry = "LACNIC"
+
+	// RegistryRIPE is the RIPE Network Coordination Centre.
+	RegistryRIPE Registry = "RIPE"
+
+	// RegistryIANA is the Internet Assigned Numbers Authority.
+	RegistryIANA Registry = "IANA"
+
+	// RegistryRADB is the Routing Assets Database.
+	RegistryRADB Registry = "RADB"
+)
+
+// RegistryInfo is the metadata of the registry.
+type RegistryInfo struct {
+	// Name is the full name of the registry.
+	Name string
+
+	// WHOISServer is the host name of the registry's WHOIS server.
+	WHOISServer string
+
+	// RDAPServer is the base URL of the registry's RDAP service. Empty when the registry has none.
+	RDAPServer string
+
+	// IsRIR reports whether the registry is a regional internet registry.
+	IsRIR bool
+
+	// HasNethandle reports whether netblocks of the registry have the Nethandle field.
+	HasNethandle bool
+}
+
+// registries is the metadata of the listed registries.
+var registries = map[Registry]RegistryInfo{
+	RegistryAFRINIC: {
+		Name:        "African Network Information Centre",
+		WHOISServer: "whois.afrinic.net",
+		RDAPServer:  "https://rdap.afrinic.net/rdap/",
+		IsRIR:       true,
+	},
+	RegistryAPNIC: {
+		Name:        "Asia-Pacific Network Information Centre",
+		WHOISServer: "whois.apnic.net",
+		RDAPServer:  "https://rdap.apnic.net/",
+		IsRIR:       true,
+	},
+	RegistryARIN: {
+		Name:         "American Registry for Internet Numbers",
+		WHOISServer:  "whois.arin.net",
+		RDAPServer:   "https://rdap.arin.net/registry/",
+		IsRIR:        true,
+		HasNethandle: true,
+	},
+	RegistryLACNIC: {
+		Name:        "Latin America and Caribbean Network Information Centre",
+		WHOISServer: "whois.lacnic.net",
+		RDAPServer:  "https://rdap.lacnic.net/rdap/",
+		IsRIR:       true,
+	},
+	RegistryRIPE: {
+		Name:        "RIPE Network Coordination Centre",
+		WHOISServer: "whois.ripe.net",
+		RDAPServer:  "https://rdap.db.ripe.net/",
+		IsRIR:       true,
+	},
+	RegistryIANA: {
+		Name:        "Internet Assigned Numbers Authority",
+		WHOISServer: "whois.iana.org",
+	},
+	RegistryRADB: {
+		Name:        "Routing Assets Database",
+		WHOISServer: "whois.radb.net",
+	},
+}
+
+// registryVariants maps the spellings of registries, lowercased and without punctuation, to the registries.
+var registryVariants = map[string]Registry{
+	"afrinic": RegistryAFRINIC,
+	"apnic":   RegistryAPNIC,
+	"arin":    RegistryARIN,
+	"lacnic":  RegistryLACNIC,
+	"ripe":    RegistryRIPE,
+	"ripencc": RegistryRIPE,
+	"iana":    RegistryIANA,
+	"radb":    RegistryRADB,
+}
+
+// ParseRegistry returns the registry ignoring case, spaces and punctuation, e.g. "ripe-ncc" or "AfriNIC".
+// An unrecognized value is returned as is.
+func ParseRegistry(s string) Registry {
+	s = strings.TrimSpace(s)
+	if r, ok := registryVariants[variantKey(s)]; ok {
+		return r
+	}
+
+	return Registry(s)
+}
+
+// IsKnown reports whether the registry is one of the listed ones.
+func (r Registry) IsKnown() bool {
+	_, ok := registries[r]
+
+	return ok
+}
+
+// Info returns the metadata of the registry. It reports false if the registry is not one of the listed ones.
+func (r Registry) Info() (RegistryInfo, bool) {
+	info, ok := registries[r]
+
+	return info, ok
+}
+
+// String returns the registry as the API does.
+func (r Registry) String() string {
+	return string(r)
+}
+
+// UnmarshalJSON decodes the registry and converts its variants to the listed registries.
+func (r *Registry) UnmarshalJSON(b []byte) error {
+	str, err := unmarshalString(b)
+	if err != nil {
+		return err
+	}
+
+	*r = ParseRegistry(str)
+	return nil
+}
+
+// MarshalJSON encodes the registry as the API does.
+func (r Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(r))
+}
+
+// ASType returns the type of the autonomous system with variants converted to the listed types.
+func (a AS) ASType() ASType {
+	return ParseASType(a.Type)
+}
+
+// Registry returns the source of the netblock with variants converted to the listed registries.
+func (i Inetnum) Registry() Registry {
+	return ParseRegistry(i.Source)
+}
+
+// variantKey returns the lowercased value without spaces and punctuation.
+func variantKey(s string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(s, isNotAlnum), ""))
+}
@@ -0,0 +1,124 @@
+package ipnetblocks
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestParseASType tests the ParseASType function.
+func TestParseASType(t *testing.T) {
+	tests := []struct {
+		s     string
+		want  ASType
+		known bool
+	}{
+		{"Cable/DSL/ISP", ASTypeISP, true},
+		{"cable dsl isp", ASTypeISP, true},
+		{" non-profit ", ASTypeNonProfit, true},
+		{"NOT_DISCLOSED", ASTypeNotDisclosed, true},
+		{"route server", ASTypeRouteServer, true},
+		{"Satellite", ASType("Satellite"), false},
+		{"", ASTypeUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got := ParseASType(tt.s)
+			if got != tt.want {
+				t.Errorf("ParseASType() got = %q, want %q", got, tt.want)
+			}
+
+			if got.IsKnown() != tt.known {
+				t.Errorf("IsKnown() got = %v, want %v", got.IsKnown(), tt.known)
+			}
+		})
+	}
+
+	if ASType("content").IsKnown() {
+		t.Error("IsKnown() got = true for the non-canonical value")
+	}
+}
+
+// TestParseRegistry tests the ParseRegistry function.
+func TestParseRegistry(t *testing.T) {
+	tests := []struct {
+		s     string
+		want  Registry
+		known bool
+	}{
+		{"ARIN", RegistryARIN, true},
+		{"ripe-ncc", RegistryRIPE, true},
+		{"AfriNIC", RegistryAFRINIC, true},
+		{"JPNIC", Registry("JPNIC"), false},
+		{"", RegistryUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got := ParseRegistry(tt.s)
+			if got != tt.want {
+				t.Errorf("ParseRegistry() got = %q, want %q", got, tt.want)
+			}
+
+			if got.IsKnown() != tt.known {
+				t.Errorf("IsKnown() got = %v, want %v", got.IsKnown(), tt.known)
+			}
+		})
+	}
+
+	info, ok := RegistryARIN.Info()
+	if !ok || info.WHOISServer != "whois.arin.net" || !info.IsRIR || !info.HasNethandle {
+		t.Errorf("Info() got = %+v, %v", info, ok)
+	}
+
+	if info, ok = RegistryRIPE.Info(); !ok || info.HasNethandle {
+		t.Errorf("Info() got = %+v, %v", info, ok)
+	}
+}
+
+// TestRegistryAccessors tests the Inetnum.Registry and AS.ASType functions.
+func TestRegistryAccessors(t *testing.T) {
+	var inetnum Inetnum
+	if err := json.Unmarshal([]byte(`{"source":"ripe ncc","as":{"type":"educational/research"}}`), &inetnum); err != nil {
+		t.Fatal(err)
+	}
+
+	if inetnum.Source != "ripe ncc" || inetnum.AS.Type != "educational/research" {
+		t.Errorf("Unmarshal() got = %q, %q", inetnum.Source, inetnum.AS.Type)
+	}
+
+	if inetnum.Registry() != RegistryRIPE || inetnum.AS.ASType() != ASTypeEducational {
+		t.Errorf("Registry(), ASType() got = %q, %q", inetnum.Registry(), inetnum.AS.ASType())
+	}
+
+	inetnum = Inetnum{Source: "JPNIC"}
+	if inetnum.Registry() != "JPNIC" || inetnum.AS.ASType() != ASTypeUnknown {
+		t.Errorf("Registry(), ASType() got = %q, %q", inetnum.Registry(), inetnum.AS.ASType())
+	}
+}
+
+// TestRegistryJSON tests JSON encoding of the AS type and the registry.
+func TestRegistryJSON(t *testing.T) {
+	var r Registry
+	if err := json.Unmarshal([]byte(`"ripe ncc"`), &r); err != nil || r != RegistryRIPE {
+		t.Errorf("Unmarshal() got = %q, %v", r, err)
+	}
+
+	var asType ASType
+	if err := json.Unmarshal([]byte(`null`), &asType); err != nil || asType != ASTypeUnknown {
+		t.Errorf("Unmarshal() got = %q, %v", asType, err)
+	}
+
+	b, err := json.Marshal(struct {
+		Type   ASType   `json:"type"`
+		Source Registry `json:"source"`
+	}{ASTypeContent, RegistryARIN})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"Content","source":"ARIN"}`
+	if string(b) != want {
+		t.Errorf("Marshal() got = %s, want %s", b, want)
+	}
+}
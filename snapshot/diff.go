@@ -0,0 +1,338 @@
+// Package snapshot compares, stores and queries crawls of IP netblocks taken at different times.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Key identifies a netblock across crawls.
+type Key struct {
+	// Range is the IP range in the "first - last" format, or the Inetnum field as is when it can't be parsed.
+	Range string `json:"range"`
+
+	// Source is the source registry of the netblock.
+	Source ipnetblocks.Registry `json:"source"`
+
+	// BGP tells netblocks obtained from BGP routing tables from registered ones with the same range.
+	BGP bool `json:"bgp,omitempty"`
+}
+
+// String returns the key in the "range (source)" format.
+func (k Key) String() string {
+	s := k.Range
+	if k.Source != "" {
+		s += " (" + string(k.Source) + ")"
+	}
+
+	if k.BGP {
+		s += " [BGP]"
+	}
+
+	return s
+}
+
+// less reports whether the key goes before the other one in address order.
+func (k Key) less(o Key) bool {
+	a, errA := ipnetblocks.ParseRange(k.Range)
+	b, errB := ipnetblocks.ParseRange(o.Range)
+
+	switch {
+	case errA != nil || errB != nil:
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+	case a != b:
+		if c := a.First.Compare(b.First); c != 0 {
+			return c < 0
+		}
+
+		return a.Last.Compare(b.Last) > 0
+	}
+
+	if k.Range != o.Range {
+		return k.Range < o.Range
+	}
+
+	if k.Source != o.Source {
+		return k.Source < o.Source
+	}
+
+	return !k.BGP && o.BGP
+}
+
+// KeyOf returns the key of the netblock.
+func KeyOf(inetnum ipnetblocks.Inetnum) Key {
+	key := Key{
+		Range:  strings.TrimSpace(inetnum.Inetnum),
+		Source: inetnum.Registry(),
+		BGP:    inetnum.Parent != "",
+	}
+
+	if r, err := inetnum.Range(); err == nil {
+		key.Range = r.String()
+	}
+
+	return key
+}
+
+// FieldChange is a change of a single netblock field.
+type FieldChange struct {
+	// Field is the name of the field, e.g. "org.name" or "as.asn".
+	Field string `json:"field"`
+
+	// Old is the value before the change.
+	Old string `json:"old"`
+
+	// New is the value after the change.
+	New string `json:"new"`
+}
+
+// Modification is a netblock present in both crawls with different data.
+type Modification struct {
+	// Key is the key of the netblock.
+	Key Key `json:"key"`
+
+	// Old is the netblock in the old crawl.
+	Old ipnetblocks.Inetnum `json:"old"`
+
+	// New is the netblock in the new crawl.
+	New ipnetblocks.Inetnum `json:"new"`
+
+	// Changes is the list of changed fields.
+	Changes []FieldChange `json:"changes"`
+}
+
+// Diff is the difference between two crawls. All lists are in address order. Lists returned by Compare are
+// never nil, so they are encoded as empty JSON arrays when there are no netblocks.
+type Diff struct {
+	// Added is the list of netblocks present only in the new crawl.
+	Added []ipnetblocks.Inetnum `json:"added"`
+
+	// Removed is the list of netblocks present only in the old crawl.
+	Removed []ipnetblocks.Inetnum `json:"removed"`
+
+	// Modified is the list of netblocks present in both crawls with different data.
+	Modified []Modification `json:"modified"`
+}
+
+// Compare returns the difference between two crawls. Netblocks are matched by range and source, and the first
+// of netblocks with the same key in a crawl is used.
+func Compare(oldInetnums, newInetnums []ipnetblocks.Inetnum) *Diff {
+	oldIndex, oldKeys := index(oldInetnums)
+	newIndex, newKeys := index(newInetnums)
+
+	diff := &Diff{
+		Added:    []ipnetblocks.Inetnum{},
+		Removed:  []ipnetblocks.Inetnum{},
+		Modified: []Modification{},
+	}
+
+	for _, key := range oldKeys {
+		if _, ok := newIndex[key]; !ok {
+			diff.Removed = append(diff.Removed, oldIndex[key])
+		}
+	}
+
+	for _, key := range newKeys {
+		newInetnum := newIndex[key]
+
+		oldInetnum, ok := oldIndex[key]
+		if !ok {
+			diff.Added = append(diff.Added, newInetnum)
+			continue
+		}
+
+		if changes := CompareFields(oldInetnum, newInetnum); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, Modification{
+				Key:     key,
+				Old:     oldInetnum,
+				New:     newInetnum,
+				Changes: changes,
+			})
+		}
+	}
+
+	return diff
+}
+
+// index returns the netblocks by key and the keys in address order.
+func index(inetnums []ipnetblocks.Inetnum) (map[Key]ipnetblocks.Inetnum, []Key) {
+	byKey := make(map[Key]ipnetblocks.Inetnum, len(inetnums))
+	keys := make([]Key, 0, len(inetnums))
+
+	for _, inetnum := range inetnums {
+		key := KeyOf(inetnum)
+		if _, ok := byKey[key]; ok {
+			continue
+		}
+
+		byKey[key] = inetnum
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].less(keys[j])
+	})
+
+	return byKey, keys
+}
+
+// IsEmpty reports whether the crawls are the same.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// WriteJSON writes the difference as an indented JSON object.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(d)
+}
+
+// WriteText writes the difference in the human-readable format, one netblock per line prefixed with "+" for
+// added, "-" for removed and "~" for modified netblocks, followed by field changes.
+func (d *Diff) WriteText(w io.Writer) error {
+	var b bytes.Buffer
+
+	for _, inetnum := range d.Removed {
+		fmt.Fprintf(&b, "- %s %s\n", KeyOf(inetnum), summary(inetnum))
+	}
+
+	for _, inetnum := range d.Added {
+		fmt.Fprintf(&b, "+ %s %s\n", KeyOf(inetnum), summary(inetnum))
+	}
+
+	for _, m := range d.Modified {
+		fmt.Fprintf(&b, "~ %s %s\n", m.Key, summary(m.New))
+		for _, c := range m.Changes {
+			fmt.Fprintf(&b, "    %s: %q -> %q\n", c.Field, c.Old, c.New)
+		}
+	}
+
+	fmt.Fprintf(&b, "%d added, %d removed, %d modified\n", len(d.Added), len(d.Removed), len(d.Modified))
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// summary returns the short description of the netblock.
+func summary(inetnum ipnetblocks.Inetnum) string {
+	s := inetnum.Netname
+	if inetnum.Org.Name != "" {
+		s += " " + strconv.Quote(inetnum.Org.Name)
+	}
+
+	if inetnum.AS.ASN != 0 {
+		s += " AS" + strconv.Itoa(inetnum.AS.ASN)
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// fields is the list of compared fields with their string representations.
+var fields = []struct {
+	name  string
+	value func(ipnetblocks.Inetnum) string
+}{
+	{"netname", func(i ipnetblocks.Inetnum) string { return i.Netname }},
+	{"nethandle", func(i ipnetblocks.Inetnum) string { return i.Nethandle }},
+	{"parent", func(i ipnetblocks.Inetnum) string { return i.Parent }},
+	{"description", func(i ipnetblocks.Inetnum) string { return strings.Join(i.Description, "\n") }},
+	{"country", func(i ipnetblocks.Inetnum) string { return i.Country }},
+	{"city", func(i ipnetblocks.Inetnum) string { return i.City }},
+	{"org.id", func(i ipnetblocks.Inetnum) string { return i.Org.Org }},
+	{"org.name", func(i ipnetblocks.Inetnum) string { return i.Org.Name }},
+	{"org.country", func(i ipnetblocks.Inetnum) string { return i.Org.Country }},
+	{"org.email", func(i ipnetblocks.Inetnum) string { return strings.Join(i.Org.Emails(), ", ") }},
+	{"org.phone", func(i ipnetblocks.Inetnum) string { return strings.Join(i.Org.Phones(), ", ") }},
+	{"as.asn", func(i ipnetblocks.Inetnum) string { return asn(i.AS.ASN) }},
+	{"as.name", func(i ipnetblocks.Inetnum) string { return i.AS.Name }},
+	{"as.type", func(i ipnetblocks.Inetnum) string { return i.AS.Type }},
+	{"as.route", func(i ipnetblocks.Inetnum) string { return i.AS.Route }},
+	{"abuseContact", func(i ipnetblocks.Inetnum) string { return contacts(i.AbuseContact) }},
+	{"adminContact", func(i ipnetblocks.Inetnum) string { return contacts(i.AdminContact) }},
+	{"techContact", func(i ipnetblocks.Inetnum) string { return contacts(i.TechContact) }},
+	{"mntBy", func(i ipnetblocks.Inetnum) string { return maintainers(i.MntBy) }},
+	{"remarks", func(i ipnetblocks.Inetnum) string { return strings.Join(i.Remarks, "\n") }},
+	{"modified", func(i ipnetblocks.Inetnum) string { return modified(i.Modified) }},
+}
+
+// CompareFields returns the changed fields of the netblock.
+func CompareFields(oldInetnum, newInetnum ipnetblocks.Inetnum) []FieldChange {
+	var changes []FieldChange
+
+	for _, field := range fields {
+		oldValue, newValue := field.value(oldInetnum), field.value(newInetnum)
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field.name, Old: oldValue, New: newValue})
+		}
+	}
+
+	return changes
+}
+
+// asn returns the AS number or an empty string if it's not set.
+func asn(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(n)
+}
+
+// contacts returns the sorted list of contacts as "ID <email> phone" entries.
+func contacts(list []ipnetblocks.Contact) string {
+	var entries []string
+	for _, c := range ipnetblocks.UniqueContacts(list) {
+		entry := c.ID
+		if entry == "" {
+			entry = c.Name()
+		}
+
+		if emails := c.Emails(); len(emails) > 0 {
+			entry = strings.TrimSpace(entry + " <" + strings.Join(emails, ", ") + ">")
+		}
+
+		if phones := c.Phones(); len(phones) > 0 {
+			entry = strings.TrimSpace(entry + " " + strings.Join(phones, ", "))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Strings(entries)
+
+	return strings.Join(entries, "; ")
+}
+
+// maintainers returns the sorted list of maintainer IDs.
+func maintainers(list []ipnetblocks.Maintainer) string {
+	var ids []string
+	for _, m := range list {
+		ids = append(ids, m.Mntner)
+	}
+
+	sort.Strings(ids)
+
+	return strings.Join(ids, ", ")
+}
+
+// modified returns the time in the RFC 3339 format, or an empty string if it's not set.
+func modified(t ipnetblocks.Time) string {
+	if time.Time(t).IsZero() {
+		return ""
+	}
+
+	return time.Time(t).UTC().Format(time.RFC3339)
+}
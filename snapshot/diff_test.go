@@ -0,0 +1,150 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// testInetnum returns the sample netblock for testing.
+func testInetnum(inetnum, netname, org string, asn int) ipnetblocks.Inetnum {
+	return ipnetblocks.Inetnum{
+		Inetnum: inetnum,
+		Netname: netname,
+		Org:     ipnetblocks.Organization{Name: org},
+		AS:      ipnetblocks.AS{ASN: asn},
+		Source:  "ARIN",
+	}
+}
+
+// TestCompare tests the Compare function.
+func TestCompare(t *testing.T) {
+	kept := testInetnum("8.8.4.0 - 8.8.4.255", "GOGL", "Google LLC", 15169)
+
+	moved := testInetnum("8.8.8.0 - 8.8.8.255", "LVLT-GOGL-8-8-8", "Google LLC", 15169)
+	moved.AbuseContact = []ipnetblocks.Contact{{ID: "ABUSE5250-ARIN", Email: "network-abuse@google.com"}}
+
+	movedNew := moved
+	movedNew.Org.Name = "Example Inc."
+	movedNew.AS.ASN = 64500
+	movedNew.Country = "US"
+	movedNew.AbuseContact = []ipnetblocks.Contact{{ID: "ABUSE1-ARIN", Email: "abuse@example.com"}}
+	movedNew.Modified = ipnetblocks.Time(time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	removed := testInetnum("8.8.9.0 - 8.8.9.255", "OLD", "Old Org", 0)
+	added := testInetnum("8.8.10.0/24", "NEW", "New Org", 0)
+
+	bgp := moved
+	bgp.Parent = "8.8.8.0 - 8.8.8.255"
+
+	diff := Compare(
+		[]ipnetblocks.Inetnum{removed, moved, kept, bgp},
+		[]ipnetblocks.Inetnum{kept, bgp, movedNew, added, kept},
+	)
+
+	if !reflect.DeepEqual(diff.Added, []ipnetblocks.Inetnum{added}) {
+		t.Errorf("Added = %v", diff.Added)
+	}
+
+	if !reflect.DeepEqual(diff.Removed, []ipnetblocks.Inetnum{removed}) {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+
+	if len(diff.Modified) != 1 {
+		t.Fatalf("Modified = %v", diff.Modified)
+	}
+
+	wantChanges := []FieldChange{
+		{Field: "country", Old: "", New: "US"},
+		{Field: "org.name", Old: "Google LLC", New: "Example Inc."},
+		{Field: "as.asn", Old: "15169", New: "64500"},
+		{Field: "abuseContact", Old: "ABUSE5250-ARIN <network-abuse@google.com>", New: "ABUSE1-ARIN <abuse@example.com>"},
+		{Field: "modified", Old: "", New: "2022-03-01T00:00:00Z"},
+	}
+
+	if !reflect.DeepEqual(diff.Modified[0].Changes, wantChanges) {
+		t.Errorf("Changes = %v, want %v", diff.Modified[0].Changes, wantChanges)
+	}
+
+	var text bytes.Buffer
+	if err := diff.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	wantText := `- 8.8.9.0 - 8.8.9.255 (ARIN) OLD "Old Org"
++ 8.8.10.0 - 8.8.10.255 (ARIN) NEW "New Org"
+~ 8.8.8.0 - 8.8.8.255 (ARIN) LVLT-GOGL-8-8-8 "Example Inc." AS64500
+    country: "" -> "US"
+    org.name: "Google LLC" -> "Example Inc."
+    as.asn: "15169" -> "64500"
+    abuseContact: "ABUSE5250-ARIN <network-abuse@google.com>" -> "ABUSE1-ARIN <abuse@example.com>"
+    modified: "" -> "2022-03-01T00:00:00Z"
+1 added, 1 removed, 1 modified
+`
+
+	if text.String() != wantText {
+		t.Errorf("WriteText() got = %s, want %s", text.String(), wantText)
+	}
+
+	var out bytes.Buffer
+	if err := diff.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Diff
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Modified[0].Changes, wantChanges) || decoded.Modified[0].Key != KeyOf(moved) {
+		t.Errorf("WriteJSON() got = %s", out.String())
+	}
+
+	same := Compare([]ipnetblocks.Inetnum{kept}, []ipnetblocks.Inetnum{kept})
+	if !same.IsEmpty() {
+		t.Error("IsEmpty() got = false for the same crawls")
+	}
+
+	out.Reset()
+	if err := same.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "{\n  \"added\": [],\n  \"removed\": [],\n  \"modified\": []\n}\n"; out.String() != want {
+		t.Errorf("WriteJSON() got = %s, want %s", out.String(), want)
+	}
+}
+
+// TestCompareFieldsPhones tests that the CompareFields function compares phones of contacts and organizations.
+func TestCompareFieldsPhones(t *testing.T) {
+	oldInetnum := testInetnum("8.8.8.0 - 8.8.8.255", "GOGL", "Google LLC", 15169)
+	oldInetnum.Org.Phone = "+1-650-253-0000"
+	oldInetnum.AbuseContact = []ipnetblocks.Contact{
+		{ID: "ABUSE5250-ARIN", Email: "network-abuse@google.com", Phone: "+1-650-253-0000"},
+	}
+
+	newInetnum := oldInetnum
+	newInetnum.Org.Phone = "+1 (650) 253-0000"
+	newInetnum.AbuseContact = []ipnetblocks.Contact{
+		{ID: "ABUSE5250-ARIN", Email: "network-abuse@google.com", Phone: "+1-650-253-0001"},
+	}
+
+	want := []FieldChange{{
+		Field: "abuseContact",
+		Old:   "ABUSE5250-ARIN <network-abuse@google.com> +16502530000",
+		New:   "ABUSE5250-ARIN <network-abuse@google.com> +16502530001",
+	}}
+
+	if got := CompareFields(oldInetnum, newInetnum); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareFields() got = %v, want %v", got, want)
+	}
+
+	newInetnum.Org.Phone = "+1-650-253-0001"
+	if got := CompareFields(oldInetnum, newInetnum); len(got) != 2 || got[0].Field != "org.phone" {
+		t.Errorf("CompareFields() got = %v, want org.phone change", got)
+	}
+}
@@ -0,0 +1,245 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// Ownership is what an IP address or prefix was associated with in a snapshot.
+type Ownership struct {
+	// Netblocks is the list of netblocks overlapping the prefix as "range (source) netname" entries,
+	// in address order.
+	Netblocks []string `json:"netblocks"`
+
+	// Orgs is the sorted list of organization names of the netblocks.
+	Orgs []string `json:"orgs"`
+
+	// ASNs is the sorted list of autonomous systems of the netblocks.
+	ASNs []int `json:"asns"`
+
+	// Countries is the sorted list of country codes of the netblocks.
+	Countries []string `json:"countries"`
+}
+
+// equal reports whether both ownerships are the same.
+func (o Ownership) equal(other Ownership) bool {
+	return reflect.DeepEqual(o, other)
+}
+
+// Period is the range of consecutive snapshots with the same ownership.
+type Period struct {
+	// From is the date of the first snapshot of the period.
+	From time.Time `json:"from"`
+
+	// To is the date of the last snapshot of the period.
+	To time.Time `json:"to"`
+
+	Ownership
+}
+
+// Transfer is the change of organizations between two consecutive periods.
+type Transfer struct {
+	// Date is the date of the first snapshot with the new organizations.
+	Date time.Time `json:"date"`
+
+	// From is the list of organizations before the transfer.
+	From []string `json:"from"`
+
+	// To is the list of organizations after the transfer.
+	To []string `json:"to"`
+}
+
+// History is the ownership timeline of an IP address or prefix.
+type History struct {
+	// Search is the IP address or prefix.
+	Search string `json:"search"`
+
+	// Periods is the list of periods in date order.
+	Periods []Period `json:"periods"`
+
+	// Transfers is the list of changes of organizations in date order. Periods without netblocks are skipped,
+	// so that an organization losing and regaining the prefix is not reported as a transfer.
+	Transfers []Transfer `json:"transfers"`
+}
+
+// History returns the timeline of netblocks, organizations, autonomous systems and countries the prefix was
+// associated with across all snapshots. Consecutive snapshots with the same ownership are merged into periods.
+func (s *Store) History(prefix netip.Prefix) (*History, error) {
+	if !prefix.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "prefix", Message: "can not be empty"}
+	}
+
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	prefix = prefix.Masked()
+	r := ipnetblocks.PrefixRange(prefix)
+
+	search := prefix.String()
+	if prefix.IsSingleIP() {
+		search = prefix.Addr().String()
+	}
+
+	history := &History{Search: search}
+
+	err := s.replay(func(date time.Time, byKey map[Key]ipnetblocks.Inetnum) bool {
+		var found []ipnetblocks.Inetnum
+		for _, inetnum := range byKey {
+			if nr, err := inetnum.Range(); err == nil && overlaps(nr, r) {
+				found = append(found, inetnum)
+			}
+		}
+
+		history.add(date, ownershipOf(found))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// add appends the snapshot to the last period, or starts a new one if the ownership has changed.
+func (h *History) add(date time.Time, ownership Ownership) {
+	if n := len(h.Periods); n > 0 && h.Periods[n-1].equal(ownership) {
+		h.Periods[n-1].To = date
+		return
+	}
+
+	if len(ownership.Orgs) > 0 {
+		for i := len(h.Periods) - 1; i >= 0; i-- {
+			previous := h.Periods[i].Orgs
+			if len(previous) == 0 {
+				continue
+			}
+
+			if !reflect.DeepEqual(previous, ownership.Orgs) {
+				h.Transfers = append(h.Transfers, Transfer{Date: date, From: previous, To: ownership.Orgs})
+			}
+
+			break
+		}
+	}
+
+	h.Periods = append(h.Periods, Period{From: date, To: date, Ownership: ownership})
+}
+
+// ownershipOf returns the ownership described by the netblocks.
+func ownershipOf(inetnums []ipnetblocks.Inetnum) Ownership {
+	orgs := make(map[string]bool)
+	asns := make(map[int]bool)
+	countries := make(map[string]bool)
+
+	var ownership Ownership
+
+	byKey, keys := index(inetnums)
+	for _, key := range keys {
+		inetnum := byKey[key]
+		ownership.Netblocks = append(ownership.Netblocks, strings.TrimSpace(KeyOf(inetnum).String()+" "+inetnum.Netname))
+
+		if name := strings.TrimSpace(inetnum.Org.Name); name != "" && !orgs[name] {
+			orgs[name] = true
+			ownership.Orgs = append(ownership.Orgs, name)
+		}
+
+		if asn := inetnum.AS.ASN; asn != 0 && !asns[asn] {
+			asns[asn] = true
+			ownership.ASNs = append(ownership.ASNs, asn)
+		}
+
+		code := strings.ToUpper(strings.TrimSpace(inetnum.Country))
+		if country, ok := ipnetblocks.LookupCountry(code); ok {
+			code = country.Code
+		}
+
+		if code != "" && !countries[code] {
+			countries[code] = true
+			ownership.Countries = append(ownership.Countries, code)
+		}
+	}
+
+	sort.Strings(ownership.Orgs)
+	sort.Ints(ownership.ASNs)
+	sort.Strings(ownership.Countries)
+
+	return ownership
+}
+
+// WriteJSON writes the history as an indented JSON object.
+func (h *History) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(h)
+}
+
+// WriteText writes the history in the human-readable format, one period per paragraph, with transfers
+// marked by "TRANSFER" lines.
+func (h *History) WriteText(w io.Writer) error {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "History of %s\n", h.Search)
+
+	transfers := make(map[time.Time]Transfer, len(h.Transfers))
+	for _, t := range h.Transfers {
+		transfers[t.Date] = t
+	}
+
+	for _, p := range h.Periods {
+		b.WriteByte('\n')
+
+		if t, ok := transfers[p.From]; ok {
+			fmt.Fprintf(&b, "TRANSFER %s: %s -> %s\n", t.Date.Format(dateLayout), quoteAll(t.From), quoteAll(t.To))
+		}
+
+		fmt.Fprintf(&b, "%s .. %s", p.From.Format(dateLayout), p.To.Format(dateLayout))
+		if len(p.Netblocks) == 0 {
+			b.WriteString("  no netblocks\n")
+			continue
+		}
+
+		if len(p.Orgs) > 0 {
+			fmt.Fprintf(&b, "  %s", quoteAll(p.Orgs))
+		}
+
+		for _, asn := range p.ASNs {
+			b.WriteString("  AS" + strconv.Itoa(asn))
+		}
+
+		if len(p.Countries) > 0 {
+			b.WriteString("  " + strings.Join(p.Countries, ","))
+		}
+
+		b.WriteByte('\n')
+
+		for _, netblock := range p.Netblocks {
+			fmt.Fprintf(&b, "    %s\n", netblock)
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// quoteAll returns the quoted strings separated by commas.
+func quoteAll(list []string) string {
+	quoted := make([]string, 0, len(list))
+	for _, s := range list {
+		quoted = append(quoted, strconv.Quote(s))
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"bytes"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// TestHistory tests the Store.History function.
+func TestHistory(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	google := testInetnum("8.8.8.0 - 8.8.8.255", "LVLT-GOGL-8-8-8", "Google LLC", 15169)
+	google.Country = "us"
+	example := testInetnum("8.8.8.0 - 8.8.8.255", "EXAMPLE-NET", "Example Inc.", 64500)
+	example.Country = "CA"
+	unrelated := testInetnum("9.0.0.0 - 9.255.255.255", "IBM", "IBM", 0)
+
+	crawls := [][]ipnetblocks.Inetnum{
+		{google},
+		{google, unrelated},
+		{unrelated},
+		{example, unrelated},
+		{example},
+	}
+
+	for i, crawl := range crawls {
+		if _, err := store.Write(day(i+1), crawl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := store.History(netip.MustParsePrefix("8.8.8.8/32"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Period{
+		{From: day(1), To: day(2), Ownership: Ownership{
+			Netblocks: []string{"8.8.8.0 - 8.8.8.255 (ARIN) LVLT-GOGL-8-8-8"},
+			Orgs:      []string{"Google LLC"},
+			ASNs:      []int{15169},
+			Countries: []string{"US"},
+		}},
+		{From: day(3), To: day(3)},
+		{From: day(4), To: day(5), Ownership: Ownership{
+			Netblocks: []string{"8.8.8.0 - 8.8.8.255 (ARIN) EXAMPLE-NET"},
+			Orgs:      []string{"Example Inc."},
+			ASNs:      []int{64500},
+			Countries: []string{"CA"},
+		}},
+	}
+
+	for i := range want {
+		want[i].From = want[i].From.Truncate(24 * time.Hour)
+		want[i].To = want[i].To.Truncate(24 * time.Hour)
+	}
+
+	if !reflect.DeepEqual(history.Periods, want) {
+		t.Errorf("Periods got = %+v, want %+v", history.Periods, want)
+	}
+
+	wantTransfers := []Transfer{{Date: want[2].From, From: []string{"Google LLC"}, To: []string{"Example Inc."}}}
+	if !reflect.DeepEqual(history.Transfers, wantTransfers) {
+		t.Errorf("Transfers got = %+v, want %+v", history.Transfers, wantTransfers)
+	}
+
+	var text bytes.Buffer
+	if err = history.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	wantText := `History of 8.8.8.8
+
+2022-03-01 .. 2022-03-02  "Google LLC"  AS15169  US
+    8.8.8.0 - 8.8.8.255 (ARIN) LVLT-GOGL-8-8-8
+
+2022-03-03 .. 2022-03-03  no netblocks
+
+TRANSFER 2022-03-04: "Google LLC" -> "Example Inc."
+2022-03-04 .. 2022-03-05  "Example Inc."  AS64500  CA
+    8.8.8.0 - 8.8.8.255 (ARIN) EXAMPLE-NET
+`
+
+	if text.String() != wantText {
+		t.Errorf("WriteText() got = %s, want %s", text.String(), wantText)
+	}
+}
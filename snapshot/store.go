@@ -0,0 +1,406 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// dateLayout is the name format of snapshot directories.
+const dateLayout = "2006-01-02"
+
+const (
+	// recordsFile is the file with netblocks added or changed since the previous snapshot.
+	recordsFile = "records.ndjson"
+
+	// removedFile is the file with keys of netblocks removed since the previous snapshot.
+	removedFile = "removed.ndjson"
+)
+
+// Store keeps crawls in dated directories suitable for committing to git. Each directory holds the netblocks
+// added or changed since the previous snapshot and the keys of the removed ones, as canonical NDJSON sorted in
+// address order, so that unchanged netblocks are stored once and diffs between commits stay small.
+type Store struct {
+	root string
+}
+
+// NewStore creates Store in the root directory. The directory is created on the first write.
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Dates returns the dates of stored snapshots in ascending order.
+func (s *Store) Dates() ([]time.Time, error) {
+	entries, err := os.ReadDir(s.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates, nil
+}
+
+// Write stores the crawl as the snapshot of the date, replacing the snapshot already stored for that date.
+// Snapshots can't be written before the latest stored one. It returns the difference from the previous snapshot.
+func (s *Store) Write(date time.Time, inetnums []ipnetblocks.Inetnum) (*Diff, error) {
+	day := date.UTC().Format(dateLayout)
+
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	// The state before the snapshot excludes the snapshot being replaced.
+	var previousDay string
+	for _, d := range dates {
+		if d.Format(dateLayout) > day {
+			return nil, &ipnetblocks.ArgError{Name: day, Message: "is before the latest snapshot"}
+		}
+
+		if d.Format(dateLayout) < day {
+			previousDay = d.Format(dateLayout)
+		}
+	}
+
+	var previous []ipnetblocks.Inetnum
+	if previousDay != "" {
+		if previous, err = s.state(previousDay); err != nil {
+			return nil, err
+		}
+	}
+
+	oldIndex, oldKeys := index(previous)
+	newIndex, newKeys := index(inetnums)
+
+	var records bytes.Buffer
+	for _, key := range newKeys {
+		line, err := canonical(newIndex[key])
+		if err != nil {
+			return nil, err
+		}
+
+		if old, ok := oldIndex[key]; ok {
+			oldLine, err := canonical(old)
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(line, oldLine) {
+				continue
+			}
+		}
+
+		records.Write(line)
+		records.WriteByte('\n')
+	}
+
+	var removed bytes.Buffer
+	for _, key := range oldKeys {
+		if _, ok := newIndex[key]; ok {
+			continue
+		}
+
+		line, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		removed.Write(line)
+		removed.WriteByte('\n')
+	}
+
+	if err = s.writeDay(day, records.Bytes(), removed.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return Compare(previous, inetnums), nil
+}
+
+// writeDay replaces the snapshot directory of the day.
+func (s *Store) writeDay(day string, records, removed []byte) error {
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(s.root, "."+day+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err = os.WriteFile(filepath.Join(tmp, recordsFile), records, 0o644); err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(tmp, removedFile), removed, 0o644); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp, 0o755); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(s.root, day)
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dir)
+}
+
+// Snapshot returns the netblocks of the latest snapshot taken on or before the date, in address order,
+// and the date of that snapshot. It returns no netblocks and the zero date if there is no such snapshot.
+func (s *Store) Snapshot(asOf time.Time) ([]ipnetblocks.Inetnum, time.Time, error) {
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	day := asOf.UTC().Format(dateLayout)
+
+	var found time.Time
+	for _, date := range dates {
+		if date.Format(dateLayout) <= day {
+			found = date
+		}
+	}
+
+	if found.IsZero() {
+		return nil, time.Time{}, nil
+	}
+
+	inetnums, err := s.state(found.Format(dateLayout))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return inetnums, found, nil
+}
+
+// GetByIP returns the netblocks containing the IP address in the latest snapshot taken on or before the date,
+// wider netblocks first.
+func (s *Store) GetByIP(ip netip.Addr, asOf time.Time) (*ipnetblocks.IPNetblocksResponse, error) {
+	if !ip.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "ip", Message: "can not be empty"}
+	}
+
+	ip = ip.Unmap()
+
+	return s.lookup(ip.String(), ipnetblocks.Range{First: ip, Last: ip}, asOf)
+}
+
+// GetByCIDR returns the netblocks overlapping the prefix in the latest snapshot taken on or before the date,
+// in address order.
+func (s *Store) GetByCIDR(prefix netip.Prefix, asOf time.Time) (*ipnetblocks.IPNetblocksResponse, error) {
+	if !prefix.IsValid() {
+		return nil, &ipnetblocks.ArgError{Name: "prefix", Message: "can not be empty"}
+	}
+
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	return s.lookup(prefix.Masked().String(), ipnetblocks.PrefixRange(prefix), asOf)
+}
+
+// lookup returns the netblocks overlapping the range as the API response.
+func (s *Store) lookup(search string, r ipnetblocks.Range, asOf time.Time) (*ipnetblocks.IPNetblocksResponse, error) {
+	inetnums, _, err := s.Snapshot(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ipnetblocks.Inetnum
+	for _, inetnum := range inetnums {
+		if nr, err := inetnum.Range(); err == nil && overlaps(nr, r) {
+			found = append(found, inetnum)
+		}
+	}
+
+	return &ipnetblocks.IPNetblocksResponse{
+		Search: search,
+		Result: ipnetblocks.Result{
+			Count:    len(found),
+			Inetnums: found,
+		},
+		Local: true,
+	}, nil
+}
+
+// state returns the netblocks as of the stored snapshot of the day by replaying all snapshots up to it.
+func (s *Store) state(day string) ([]ipnetblocks.Inetnum, error) {
+	var inetnums []ipnetblocks.Inetnum
+
+	err := s.replay(func(date time.Time, byKey map[Key]ipnetblocks.Inetnum) bool {
+		if date.Format(dateLayout) != day {
+			return date.Format(dateLayout) < day
+		}
+
+		inetnums = sortedInetnums(byKey)
+		return false
+	})
+
+	return inetnums, err
+}
+
+// replay applies snapshots one by one in date order, and calls fn with the netblocks as of each snapshot until
+// it returns false. The map must not be modified or kept.
+func (s *Store) replay(fn func(date time.Time, byKey map[Key]ipnetblocks.Inetnum) bool) error {
+	dates, err := s.Dates()
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[Key]ipnetblocks.Inetnum)
+
+	for _, date := range dates {
+		dir := filepath.Join(s.root, date.Format(dateLayout))
+
+		err = readNDJSON(filepath.Join(dir, removedFile), func(dec *json.Decoder) error {
+			var key Key
+			if err := dec.Decode(&key); err != nil {
+				return err
+			}
+
+			delete(byKey, key)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = readNDJSON(filepath.Join(dir, recordsFile), func(dec *json.Decoder) error {
+			var inetnum ipnetblocks.Inetnum
+			if err := dec.Decode(&inetnum); err != nil {
+				return err
+			}
+
+			byKey[KeyOf(inetnum)] = inetnum
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if !fn(date, byKey) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// sortedInetnums returns the netblocks in address order.
+func sortedInetnums(byKey map[Key]ipnetblocks.Inetnum) []ipnetblocks.Inetnum {
+	keys := make([]Key, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].less(keys[j])
+	})
+
+	inetnums := make([]ipnetblocks.Inetnum, 0, len(keys))
+	for _, key := range keys {
+		inetnums = append(inetnums, byKey[key])
+	}
+
+	return inetnums
+}
+
+// readNDJSON calls decode for every value of the file. A missing file has no values.
+func readNDJSON(path string, decode func(dec *json.Decoder) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		if err = decode(dec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return &ipnetblocks.ArgError{Name: path, Message: "is corrupted: " + err.Error()}
+		}
+	}
+}
+
+// canonical returns the netblock as a single line of JSON. Missing lists are encoded as empty ones, so that
+// netblocks decoded from the API and from the store are encoded the same way.
+func canonical(inetnum ipnetblocks.Inetnum) ([]byte, error) {
+	for _, list := range []*[]string{&inetnum.Description, &inetnum.Address, &inetnum.Remarks} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	for _, list := range []*[]ipnetblocks.Contact{&inetnum.AbuseContact, &inetnum.AdminContact, &inetnum.TechContact} {
+		contacts := make([]ipnetblocks.Contact, len(*list))
+		copy(contacts, *list)
+
+		for i := range contacts {
+			if contacts[i].Address == nil {
+				contacts[i].Address = []string{}
+			}
+		}
+
+		*list = contacts
+	}
+
+	for _, list := range []*[]ipnetblocks.Maintainer{
+		&inetnum.MntBy, &inetnum.MntDomains, &inetnum.MntLower, &inetnum.MntRoutes,
+	} {
+		if *list == nil {
+			*list = []ipnetblocks.Maintainer{}
+		}
+	}
+
+	if inetnum.Org.Address == nil {
+		inetnum.Org.Address = []string{}
+	}
+
+	return json.Marshal(inetnum)
+}
+
+// overlaps reports whether the ranges have common addresses.
+func overlaps(a, b ipnetblocks.Range) bool {
+	return a.First.BitLen() == b.First.BitLen() && a.First.Compare(b.Last) <= 0 && b.First.Compare(a.Last) <= 0
+}
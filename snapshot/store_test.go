@@ -0,0 +1,139 @@
+package snapshot
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ipnetblocks "github.com/whois-api-llc/ip-netblocks-go"
+)
+
+// day returns the date of March of 2022.
+func day(n int) time.Time {
+	return time.Date(2022, 3, n, 12, 0, 0, 0, time.UTC)
+}
+
+// netnames returns netnames of the response netblocks.
+func netnames(resp *ipnetblocks.IPNetblocksResponse) string {
+	var names []string
+	for _, inetnum := range resp.Result.Inetnums {
+		names = append(names, inetnum.Netname)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// TestStore tests the Store functions.
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+
+	parent := testInetnum("8.0.0.0 - 8.127.255.255", "LVLT-ORG-8-8", "Level 3 Parent, LLC", 3356)
+	google := testInetnum("8.8.8.0 - 8.8.8.255", "LVLT-GOGL-8-8-8", "Google LLC", 15169)
+	example := google
+	example.Org.Name = "Example Inc."
+	other := testInetnum("9.0.0.0 - 9.255.255.255", "IBM", "IBM", 0)
+
+	if _, err := store.Write(day(1), []ipnetblocks.Inetnum{google, parent}); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := store.Write(day(3), []ipnetblocks.Inetnum{parent, example, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Added) != 1 || len(diff.Modified) != 1 || len(diff.Removed) != 0 {
+		t.Errorf("Write() diff = %+v", diff)
+	}
+
+	if _, err = store.Write(day(5), []ipnetblocks.Inetnum{parent, example}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := os.ReadFile(filepath.Join(root, "2022-03-03", "records.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(records)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "Example Inc.") || !strings.Contains(lines[1], `"IBM"`) {
+		t.Errorf("records.ndjson = %s", records)
+	}
+
+	removed, err := os.ReadFile(filepath.Join(root, "2022-03-05", "removed.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"range":"9.0.0.0 - 9.255.255.255","source":"ARIN"}` + "\n"; string(removed) != want {
+		t.Errorf("removed.ndjson = %s, want %s", removed, want)
+	}
+
+	tests := []struct {
+		asOf time.Time
+		ip   string
+		want string
+	}{
+		{day(1).Add(-24 * time.Hour), "8.8.8.8", ""},
+		{day(1), "8.8.8.8", "LVLT-ORG-8-8,LVLT-GOGL-8-8-8"},
+		{day(2), "8.8.8.8", "LVLT-ORG-8-8,LVLT-GOGL-8-8-8"},
+		{day(4), "9.9.9.9", "IBM"},
+		{day(30), "9.9.9.9", ""},
+	}
+
+	for _, tt := range tests {
+		resp, err := store.GetByIP(netip.MustParseAddr(tt.ip), tt.asOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := netnames(resp); got != tt.want {
+			t.Errorf("GetByIP(%s, %s) got = %q, want %q", tt.ip, tt.asOf.Format(dateLayout), got, tt.want)
+		}
+	}
+
+	resp, err := store.GetByIP(netip.MustParseAddr("8.8.8.8"), day(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if org := resp.Result.Inetnums[1].Org.Name; org != "Example Inc." {
+		t.Errorf("GetByIP() org = %q", org)
+	}
+
+	resp, err = store.GetByCIDR(netip.MustParsePrefix("8.0.0.0/7"), day(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := netnames(resp); got != "LVLT-ORG-8-8,LVLT-GOGL-8-8-8,IBM" {
+		t.Errorf("GetByCIDR() got = %q", got)
+	}
+
+	// Replacing the latest snapshot is allowed, writing before it is not.
+	if _, err = store.Write(day(5), []ipnetblocks.Inetnum{parent}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = store.Write(day(4), []ipnetblocks.Inetnum{parent}); err == nil {
+		t.Error("Write() expected error")
+	}
+
+	inetnums, date, err := store.Snapshot(day(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inetnums) != 1 || !date.Equal(time.Date(2022, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Snapshot() got = %v, %v", inetnums, date)
+	}
+
+	dates, err := store.Dates()
+	if err != nil || len(dates) != 3 {
+		t.Errorf("Dates() got = %v, %v", dates, err)
+	}
+}
@@ -0,0 +1,176 @@
+package ipnetblocks
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// SpecialPurposeMode defines how requests for special-purpose addresses are handled.
+type SpecialPurposeMode int
+
+const (
+	// SpecialPurposeQuery sends requests for special-purpose addresses to the API as any other requests.
+	SpecialPurposeQuery SpecialPurposeMode = iota
+
+	// SpecialPurposeLocal answers requests for special-purpose addresses with a locally generated response.
+	SpecialPurposeLocal
+
+	// SpecialPurposeReject rejects requests for special-purpose addresses with SpecialPurposeError.
+	SpecialPurposeReject
+)
+
+// SpecialPurposeBlock is an entry of the IANA IPv4 and IPv6 Special-Purpose Address Registries.
+// Multicast blocks are included as well.
+type SpecialPurposeBlock struct {
+	// Prefix is the address block.
+	Prefix netip.Prefix
+
+	// Name is the name of the block.
+	Name string
+
+	// RFC is the document which defines the block.
+	RFC string
+
+	// Global indicates that addresses of the block are globally reachable, and thus registered as regular netblocks.
+	Global bool
+}
+
+// SpecialPurposeError is returned when a request for a special-purpose address is rejected.
+type SpecialPurposeError struct {
+	// Search is the requested IP address or CIDR.
+	Search string
+
+	// Block is the special-purpose block the request belongs to.
+	Block SpecialPurposeBlock
+}
+
+// Error returns error message as a string.
+func (e *SpecialPurposeError) Error() string {
+	return `special-purpose address: "` + e.Search + `" belongs to ` + e.Block.Prefix.String() +
+		" (" + e.Block.Name + ", " + e.Block.RFC + ")"
+}
+
+//go:embed data/special-purpose.csv
+var specialPurposeCSV []byte
+
+// specialPurposeBlocks is the parsed special-purpose address registry.
+var specialPurposeBlocks = mustParseSpecialPurpose(specialPurposeCSV)
+
+// mustParseSpecialPurpose parses the embedded special-purpose address registry.
+func mustParseSpecialPurpose(raw []byte) []SpecialPurposeBlock {
+	records, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	blocks := make([]SpecialPurposeBlock, 0, len(records))
+	for _, record := range records[1:] {
+		global, err := strconv.ParseBool(record[3])
+		if err != nil {
+			panic(err)
+		}
+
+		blocks = append(blocks, SpecialPurposeBlock{
+			Prefix: netip.MustParsePrefix(record[0]),
+			Name:   record[1],
+			RFC:    record[2],
+			Global: global,
+		})
+	}
+
+	return blocks
+}
+
+// LookupSpecialPurpose returns the most specific special-purpose block which contains the whole prefix.
+func LookupSpecialPurpose(prefix netip.Prefix) (SpecialPurposeBlock, bool) {
+	var found SpecialPurposeBlock
+	var ok bool
+
+	prefix = prefix.Masked()
+	for _, block := range specialPurposeBlocks {
+		if block.Prefix.Bits() > prefix.Bits() || !block.Prefix.Contains(prefix.Addr()) {
+			continue
+		}
+
+		if !ok || block.Prefix.Bits() > found.Prefix.Bits() {
+			found, ok = block, true
+		}
+	}
+
+	return found, ok
+}
+
+// IsSpecialPurpose reports whether the address belongs to a special-purpose block which is not globally reachable.
+func IsSpecialPurpose(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	block, ok := LookupSpecialPurpose(netip.PrefixFrom(ip, ip.BitLen()))
+
+	return ok && !block.Global
+}
+
+// specialPurpose returns the response to use instead of the API call for a special-purpose prefix according to
+// the client mode. Both return values are nil when the request should be sent to the API.
+func (service ipNetblocksServiceOp) specialPurpose(search string, prefix netip.Prefix) (*IPNetblocksResponse, error) {
+	if service.client.specialPurpose == SpecialPurposeQuery || !prefix.IsValid() {
+		return nil, nil
+	}
+
+	block, ok := LookupSpecialPurpose(prefix)
+	if !ok || block.Global {
+		return nil, nil
+	}
+
+	if service.client.specialPurpose == SpecialPurposeReject {
+		return nil, &SpecialPurposeError{Search: search, Block: block}
+	}
+
+	return specialPurposeResponse(search, block), nil
+}
+
+// specialPurposeResponse returns the locally generated response for the special-purpose block.
+func specialPurposeResponse(search string, block SpecialPurposeBlock) *IPNetblocksResponse {
+	r := PrefixRange(block.Prefix)
+	first, last := addrInt(r.First), addrInt(r.Last)
+	firstFloat, _ := new(big.Float).SetInt(first).Float64()
+	lastFloat, _ := new(big.Float).SetInt(last).Float64()
+
+	inetnum := Inetnum{
+		Inetnum:            r.String(),
+		InetnumFirst:       firstFloat,
+		InetnumLast:        lastFloat,
+		InetnumFirstString: first.String(),
+		InetnumLastString:  last.String(),
+		Netname:            "IANA-" + strings.ToUpper(strings.Join(strings.FieldsFunc(block.Name, isNotAlnum), "-")),
+		Description:        []string{block.Name, block.RFC},
+		Remarks:            []string{"Locally generated from the IANA Special-Purpose Address Registry"},
+		Source:             "IANA",
+	}
+
+	return &IPNetblocksResponse{
+		Search: search,
+		Result: Result{
+			Count:    1,
+			Limit:    1,
+			Inetnums: []Inetnum{inetnum},
+		},
+		Local: true,
+	}
+}
+
+// addrInt returns the address as 128-bit unsigned integer, IPv4 addresses are mapped to IPv6 as the API does.
+func addrInt(ip netip.Addr) *big.Int {
+	b := ip.As16()
+
+	return new(big.Int).SetBytes(b[:])
+}
+
+// isNotAlnum reports whether the rune is neither a letter nor a digit.
+func isNotAlnum(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+}
@@ -0,0 +1,127 @@
+package ipnetblocks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+// TestLookupSpecialPurpose tests the LookupSpecialPurpose function.
+func TestLookupSpecialPurpose(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+		global bool
+		found  bool
+	}{
+		{prefix: "10.1.2.3/32", want: "10.0.0.0/8", found: true},
+		{prefix: "192.168.0.0/24", want: "192.168.0.0/16", found: true},
+		{prefix: "100.64.0.1/32", want: "100.64.0.0/10", found: true},
+		{prefix: "192.0.0.9/32", want: "192.0.0.9/32", global: true, found: true},
+		{prefix: "239.1.1.1/32", want: "224.0.0.0/4", found: true},
+		{prefix: "2001:db8::1/128", want: "2001:db8::/32", found: true},
+		{prefix: "2001::1/128", want: "2001::/32", global: true, found: true},
+		{prefix: "fe80::1/128", want: "fe80::/10", found: true},
+		{prefix: "10.0.0.0/7", found: false},
+		{prefix: "8.8.8.8/32", found: false},
+		{prefix: "2001:4860::/32", found: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, ok := LookupSpecialPurpose(netip.MustParsePrefix(tt.prefix))
+			if ok != tt.found {
+				t.Fatalf("LookupSpecialPurpose() found = %v, want %v", ok, tt.found)
+			}
+
+			if ok && (got.Prefix.String() != tt.want || got.Global != tt.global) {
+				t.Errorf("LookupSpecialPurpose() = %v, want %v (global %v)", got, tt.want, tt.global)
+			}
+		})
+	}
+}
+
+// TestIPNetblocksSpecialPurpose tests GetByIP and GetByCIDR with special-purpose addresses.
+func TestIPNetblocksSpecialPurpose(t *testing.T) {
+	ctx := context.Background()
+
+	server := pagedServer(map[string]string{})
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newSpecialAPI := func(mode SpecialPurposeMode) *Client {
+		return NewClient(apiKey, ClientParams{
+			HTTPClient:         server.Client(),
+			IPNetblocksBaseURL: apiURL,
+			SpecialPurpose:     mode,
+		})
+	}
+
+	t.Run("local", func(t *testing.T) {
+		api := newSpecialAPI(SpecialPurposeLocal)
+
+		got, resp, err := api.GetByIP(ctx, net.ParseIP("192.168.1.1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp != nil || !got.Local || got.Result.Count != 1 {
+			t.Fatalf("IPNetblocks.GetByIP() got = %v, expected local response", got)
+		}
+
+		inetnum := got.Result.Inetnums[0]
+		if inetnum.Inetnum != "192.168.0.0 - 192.168.255.255" || inetnum.Netname != "IANA-PRIVATE-USE" ||
+			inetnum.InetnumFirstString != "281473913978880" || inetnum.Source != "IANA" {
+			t.Errorf("IPNetblocks.GetByIP() got = %+v", inetnum)
+		}
+
+		got, _, err = api.GetByCIDR(ctx, net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(64, 128)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Local || got.Result.Inetnums[0].Inetnum != "fc00:: - fdff:ffff:ffff:ffff:ffff:ffff:ffff:ffff" {
+			t.Errorf("IPNetblocks.GetByCIDR() got = %+v, expected local response", got)
+		}
+
+		got, _, err = api.GetByIP(ctx, net.ParseIP("8.8.8.8"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Local {
+			t.Errorf("IPNetblocks.GetByIP() got = %+v, expected API response", got)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		api := newSpecialAPI(SpecialPurposeReject)
+
+		got, _, err := api.GetByIP(ctx, net.ParseIP("127.0.0.1"))
+		checkErr(t, err, `special-purpose address: "127.0.0.1" belongs to 127.0.0.0/8 (Loopback, RFC1122)`)
+
+		var spErr *SpecialPurposeError
+		if got != nil || !errors.As(err, &spErr) {
+			t.Errorf("IPNetblocks.GetByIP() got = %v, err = %v, expected SpecialPurposeError", got, err)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		api := newSpecialAPI(SpecialPurposeQuery)
+
+		got, _, err := api.GetByIP(ctx, net.ParseIP("127.0.0.1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Local {
+			t.Errorf("IPNetblocks.GetByIP() got = %+v, expected API response", got)
+		}
+	})
+}
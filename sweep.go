@@ -0,0 +1,218 @@
+package ipnetblocks
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+// SweeperParams is used to create Sweeper. None of parameters are mandatory.
+type SweeperParams struct {
+	// Workers is the maximum number of concurrent requests. Default: 1.
+	Workers int
+
+	// MaxPrefixLen is the longest prefix the sweeper splits queries into. When a query of this length still
+	// doesn't fit into the limit, its pages are fetched one by one. Zero means the address length.
+	MaxPrefixLen int
+}
+
+// Sweeper crawls large CIDRs by splitting each query into halves while its results exceed the limit.
+type Sweeper struct {
+	service IPNetblocks
+
+	workers      int
+	maxPrefixLen int
+}
+
+// NewSweeper creates Sweeper which sends requests through the specified service.
+func NewSweeper(service IPNetblocks, params SweeperParams) *Sweeper {
+	workers := params.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Sweeper{
+		service:      service,
+		workers:      workers,
+		maxPrefixLen: params.MaxPrefixLen,
+	}
+}
+
+// Sweep returns all netblocks found within the CIDR. A query whose page comes back full is replaced by
+// queries for both of its halves, which are run concurrently. The results are merged in address order,
+// and netblocks returned by several queries are included only once.
+func (s *Sweeper) Sweep(ctx context.Context, ip net.IPNet, opts ...Option) (*IPNetblocksResponse, error) {
+	if ip.IP.String() == "<nil>" {
+		return nil, &ArgError{"ip", "can not be empty"}
+	}
+
+	prefix, _, err := InputParams{}.normalizeIPNet(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, s.workers)
+
+	inetnums, err := s.sweep(ctx, cancel, sem, prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	inetnums = mergeInetnums(inetnums)
+
+	return &IPNetblocksResponse{
+		Search: prefix.String(),
+		Result: Result{
+			Count:    len(inetnums),
+			Inetnums: inetnums,
+		},
+	}, nil
+}
+
+// sweep returns netblocks found within the prefix, splitting it when the first page is full.
+func (s *Sweeper) sweep(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	sem chan struct{},
+	prefix netip.Prefix,
+	opts []Option,
+) ([]Inetnum, error) {
+	resp, err := s.page(ctx, sem, prefix, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	full := resp.Result.Next != nil && resp.Result.Count >= resp.Result.Limit
+	if full && prefix.Bits() < s.prefixLenLimit(prefix) {
+		lower := netip.PrefixFrom(prefix.Addr(), prefix.Bits()+1)
+		upper := netip.PrefixFrom(lastAddr(lower).Next(), prefix.Bits()+1)
+
+		var wg sync.WaitGroup
+		var results [2][]Inetnum
+		var errs [2]error
+
+		for i, half := range []netip.Prefix{lower, upper} {
+			wg.Add(1)
+			go func(i int, half netip.Prefix) {
+				defer wg.Done()
+
+				results[i], errs[i] = s.sweep(ctx, cancel, sem, half, opts)
+				if errs[i] != nil {
+					cancel()
+				}
+			}(i, half)
+		}
+
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return append(results[0], results[1]...), nil
+	}
+
+	inetnums := resp.Result.Inetnums
+	for from := resp.Result.Next; from != nil; {
+		resp, err = s.page(ctx, sem, prefix, from, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		inetnums = append(inetnums, resp.Result.Inetnums...)
+
+		// A page pointing to itself would be fetched forever.
+		next := resp.Result.Next
+		if next != nil && *next == *from {
+			break
+		}
+		from = next
+	}
+
+	return inetnums, nil
+}
+
+// page returns a single page of results for the prefix, waiting for a free worker. The first page is requested
+// without the "from" parameter even if the caller passed OptionFrom.
+func (s *Sweeper) page(
+	ctx context.Context,
+	sem chan struct{},
+	prefix netip.Prefix,
+	from *string,
+	opts []Option,
+) (*IPNetblocksResponse, error) {
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-sem }()
+
+	pageOpts := make([]Option, 0, len(opts)+1)
+	pageOpts = append(pageOpts, opts...)
+	pageOpts = append(pageOpts, optionPage(from))
+
+	resp, _, err := s.service.GetByCIDR(ctx, prefixIPNet(prefix), pageOpts...)
+
+	return resp, err
+}
+
+// prefixLenLimit returns the longest prefix length the sweeper may split queries into.
+func (s *Sweeper) prefixLenLimit(prefix netip.Prefix) int {
+	if s.maxPrefixLen > 0 && s.maxPrefixLen < prefix.Addr().BitLen() {
+		return s.maxPrefixLen
+	}
+
+	return prefix.Addr().BitLen()
+}
+
+// mergeInetnums removes duplicate netblocks and sorts them by the first address, wider blocks first.
+// Netblocks with unparsable ranges are kept at the end in their original order.
+func mergeInetnums(inetnums []Inetnum) []Inetnum {
+	type entry struct {
+		inetnum Inetnum
+		r       Range
+		ok      bool
+	}
+
+	seen := make(map[string]bool, len(inetnums))
+	entries := make([]entry, 0, len(inetnums))
+
+	for _, inetnum := range inetnums {
+		key := inetnum.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		r, err := inetnum.Range()
+		entries = append(entries, entry{inetnum: inetnum, r: r, ok: err == nil})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.ok || !b.ok {
+			return a.ok && !b.ok
+		}
+
+		if c := a.r.First.Compare(b.r.First); c != 0 {
+			return c < 0
+		}
+
+		return a.r.Last.Compare(b.r.Last) > 0
+	})
+
+	merged := make([]Inetnum, 0, len(entries))
+	for _, e := range entries {
+		merged = append(merged, e.inetnum)
+	}
+
+	return merged
+}
@@ -0,0 +1,120 @@
+package ipnetblocks
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// TestSweeperSweep tests the Sweeper.Sweep function.
+func TestSweeperSweep(t *testing.T) {
+	ctx := context.Background()
+
+	server := pagedServer(map[string]string{
+		"10.0.0.0/30": `{"search":"10.0.0.0/30","result":{"count":2,"limit":2,"from":null,"next":"10.0.0.1-10.0.0.1",
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"},{"inetnum":"10.0.0.1 - 10.0.0.1","source":"RIPE"}]}}`,
+		"10.0.0.0/31": `{"search":"10.0.0.0/31","result":{"count":2,"limit":2,"from":null,"next":"10.0.0.1-10.0.0.1",
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"},{"inetnum":"10.0.0.1 - 10.0.0.1","source":"RIPE"}]}}`,
+		"10.0.0.0/32": `{"search":"10.0.0.0/32","result":{"count":1,"limit":2,"from":null,"next":null,
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"}]}}`,
+		"10.0.0.1/32": `{"search":"10.0.0.1/32","result":{"count":2,"limit":2,"from":null,"next":"10.0.0.1-10.0.0.1",
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"},{"inetnum":"10.0.0.1 - 10.0.0.1","source":"RIPE"}]}}`,
+		"10.0.0.1/32|10.0.0.1-10.0.0.1": `{"search":"10.0.0.1/32","result":{"count":1,"limit":2,"from":"10.0.0.1-10.0.0.1",
+"next":null,"inetnums":[{"inetnum":"10.0.0.1 - 10.0.0.1","source":"RADB","parent":"10.0.0.0 - 10.255.255.255"}]}}`,
+		"10.0.0.4/31": `{"search":"10.0.0.4/31","result":{"count":1,"limit":1,"from":null,"next":"10.0.0.4-10.0.0.4",
+"inetnums":[{"inetnum":"10.0.0.4 - 10.0.0.4","source":"RIPE"}]}}`,
+		"10.0.0.4/31|10.0.0.4-10.0.0.4": `{"search":"10.0.0.4/31","result":{"count":1,"limit":1,"from":"10.0.0.4-10.0.0.4",
+"next":"10.0.0.4-10.0.0.4","inetnums":[{"inetnum":"10.0.0.5 - 10.0.0.5","source":"RIPE"}]}}`,
+		"10.0.0.2/31": `{"search":"10.0.0.2/31","result":{"count":2,"limit":2,"from":null,"next":null,
+"inetnums":[{"inetnum":"10.0.0.0 - 10.255.255.255","source":"IANA"},{"inetnum":"10.0.0.2 - 10.0.0.3","source":"RIPE"}]}}`,
+	})
+	defer server.Close()
+
+	sweepFrom := "10.0.0.1-10.0.0.1"
+
+	tests := []struct {
+		name    string
+		cidr    string
+		params  SweeperParams
+		opts    []Option
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "split until fits",
+			cidr:   "10.0.0.0/30",
+			params: SweeperParams{Workers: 3},
+			want: []string{
+				"10.0.0.0 - 10.255.255.255",
+				"10.0.0.1 - 10.0.0.1",
+				"10.0.0.1 - 10.0.0.1",
+				"10.0.0.2 - 10.0.0.3",
+			},
+		},
+		{
+			name:   "caller from ignored",
+			cidr:   "10.0.0.0/30",
+			params: SweeperParams{Workers: 3},
+			opts:   []Option{OptionFrom(&sweepFrom)},
+			want: []string{
+				"10.0.0.0 - 10.255.255.255",
+				"10.0.0.1 - 10.0.0.1",
+				"10.0.0.1 - 10.0.0.1",
+				"10.0.0.2 - 10.0.0.3",
+			},
+		},
+		{
+			name:   "stuck next",
+			cidr:   "10.0.0.4/31",
+			params: SweeperParams{MaxPrefixLen: 31},
+			want: []string{
+				"10.0.0.4 - 10.0.0.4",
+				"10.0.0.5 - 10.0.0.5",
+			},
+		},
+		{
+			name:   "max prefix length",
+			cidr:   "10.0.0.0/30",
+			params: SweeperParams{MaxPrefixLen: 30},
+			want: []string{
+				"10.0.0.0 - 10.255.255.255",
+				"10.0.0.1 - 10.0.0.1",
+			},
+		},
+		{
+			name:    "invalid argument",
+			cidr:    "",
+			wantErr: `invalid argument: "ip" can not be empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sweeper := NewSweeper(newAPI(server, "/"), tt.params)
+
+			var ipNet net.IPNet
+			if tt.cidr != "" {
+				_, n, err := net.ParseCIDR(tt.cidr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ipNet = *n
+			}
+
+			gotRec, err := sweeper.Sweep(ctx, ipNet, tt.opts...)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+
+			var got []string
+			for _, inetnum := range gotRec.Result.Inetnums {
+				got = append(got, inetnum.Inetnum)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sweeper.Sweep() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}